@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/internal/service"
+	"github.com/example/golang-project/pkg/db"
+)
+
+// runCreateUser implements `users create-user`, used to bootstrap the first account
+// since every user endpoint requires a token.
+//
+//	users create-user -name "Admin" -email admin@example.com -role admin -password '...'
+//
+// The password may also be supplied through the USER_PASSWORD environment variable
+// to keep it out of shell history.
+func runCreateUser(dbConn *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	name := flags.String("name", "", "display name")
+	email := flags.String("email", "", "login email")
+	role := flags.String("role", "admin", "role: admin, staff, member or viewer")
+	password := flags.String("password", os.Getenv("USER_PASSWORD"), "password (or USER_PASSWORD env)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	svc := service.NewUserService(repository.NewUserRepository(db.NewRouter(dbConn)), db.NewUnitOfWork(dbConn))
+	id, err := svc.CreateUser(context.Background(), &model.User{Name: *name, Email: *email, Role: *role, Password: *password})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created user %d (%s, %s)\n", id, *email, *role)
+	return nil
+}
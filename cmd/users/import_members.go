@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/internal/service"
+	"github.com/example/golang-project/pkg/db"
+)
+
+// runImportMembers implements `users import-members`, the command-line twin of
+// POST /members/import for files too large to upload.
+//
+//	users import-members -file members.csv -column name="Full Name" -column email=E-mail -dry-run -report errors.csv
+//
+// It prints a summary and writes the rows that failed as CSV to -report (default stdout).
+// The exit status is non-zero when any row failed.
+func runImportMembers(dbConn *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("import-members", flag.ContinueOnError)
+	path := flags.String("file", "", "CSV file to import (- for stdin)")
+	dryRun := flags.Bool("dry-run", false, "check the file without saving anything")
+	batchSize := flags.Int("batch-size", 0, "rows per transaction (default 100)")
+	reportPath := flags.String("report", "", "where to write the rows that failed as CSV (default stdout)")
+	columns := map[string]string{}
+	flags.Func("column", "field=Header: read a member field from a differently named column (repeatable)", func(v string) error {
+		field, header, ok := strings.Cut(v, "=")
+		if !ok {
+			return errors.New("want field=Header")
+		}
+		columns[strings.TrimSpace(field)] = header
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("-file is required")
+	}
+
+	in := os.Stdin
+	if *path != "-" {
+		f, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	svc := service.NewChurchMemberService(repository.NewChurchMemberRepository(db.NewRouter(dbConn)), db.NewUnitOfWork(dbConn))
+	report, err := svc.ImportMembers(context.Background(), in, model.ImportOptions{
+		Columns: columns, DryRun: *dryRun, BatchSize: *batchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if report.DryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(os.Stderr, "%s%d rows: %d created, %d updated, %d unchanged, %d failed\n",
+		prefix, report.Rows, report.Created, report.Updated, report.Unchanged, report.Failed)
+	if report.Failed == 0 {
+		return nil
+	}
+
+	out := os.Stdout
+	if *reportPath != "" {
+		f, err := os.Create(*reportPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := service.WriteImportReport(out, report); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d rows failed", report.Failed)
+}
@@ -5,53 +5,137 @@
 // @host localhost:8080
 // @basePath /
 // @schemes http
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the access token from POST /auth/login.
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 
 	_ "github.com/example/golang-project/docs"
 	"github.com/example/golang-project/internal/server"
+	"github.com/example/golang-project/migrations"
 	"github.com/example/golang-project/pkg/db"
 	cfg "github.com/example/golang-project/pkg/db/config"
+	"github.com/example/golang-project/pkg/db/migrate"
+	"github.com/example/golang-project/pkg/logger"
 )
 
 // main is the service entrypoint. It loads configuration (from config/appsettings.json
 // or environment variables), connects to the DB and starts the HTTP server.
+//
+// Subcommands:
+//
+//	users                      start the HTTP server
+//	users migrate <command>    manage the database schema (see runMigrate)
+//	users create-user ...      create a user who can log in (see runCreateUser)
+//	users import-members ...   import church members from CSV (see runImportMembers)
 func main() {
+	conf := loadConfig()
+
+	// slog.SetDefault also routes the standard log package through the same handler.
+	slog.SetDefault(logger.New(os.Stderr, conf.Logging.Format, conf.Logging.Level))
+
+	if conf.Database.ConnectionString == "" {
+		log.Fatal("database connection string is required (set DB_CONN or config/appsettings.json)")
+	}
+
+	dbConn, err := db.ConnectDB(conf.Database.ConnectionString)
+	if err != nil {
+		log.Fatalf("failed to connect to db: %v", err)
+	}
+
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "migrate":
+			err = runMigrate(dbConn, os.Args[2:])
+		case "create-user":
+			err = runCreateUser(dbConn, os.Args[2:])
+		case "import-members":
+			err = runImportMembers(dbConn, os.Args[2:])
+		default:
+			err = fmt.Errorf("unknown command %q (expected migrate, create-user or import-members)", os.Args[1])
+		}
+		dbConn.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	if err := checkSchema(dbConn, conf.Database.MigrationMode); err != nil {
+		dbConn.Close()
+		log.Fatalf("schema check failed: %v", err)
+	}
+
+	// server.Run closes dbConn once in-flight requests have drained.
+	if err := server.Run(conf, dbConn); err != nil {
+		log.Fatalf("server stopped with error: %v", err)
+	}
+}
+
+// loadConfig reads config/appsettings.json if present, otherwise falls back to environment variables.
+func loadConfig() *cfg.Config {
 	configPath := "config/appsettings.json"
 
-	var conf *cfg.Config
 	if _, err := os.Stat(configPath); err == nil {
 		c, err := cfg.Load(configPath)
 		if err != nil {
 			log.Fatalf("failed to load config: %v", err)
 		}
-		conf = c
-	} else {
-		// fallback to environment variables
-		conf = &cfg.Config{}
-		conf.Database.ConnectionString = os.Getenv("DB_CONN")
-		conf.Server.Addr = os.Getenv("ADDR")
+		return c
 	}
 
-	if conf.Database.ConnectionString == "" {
-		log.Fatal("database connection string is required (set DB_CONN or config/appsettings.json)")
+	// fallback to environment variables
+	conf := &cfg.Config{}
+	conf.Database.ConnectionString = os.Getenv("DB_CONN")
+	conf.Database.MigrationMode = os.Getenv("DB_MIGRATION_MODE")
+	if v := os.Getenv("DB_REPLICAS"); v != "" {
+		conf.Database.ReplicaConnectionStrings = strings.Split(v, ",")
 	}
+	conf.Server.Addr = os.Getenv("ADDR")
+	conf.Auth.JWTSecret = os.Getenv("JWT_SECRET")
+	conf.Logging.Level = os.Getenv("LOG_LEVEL")
+	return conf
+}
 
-	dbConn, err := db.ConnectDB(conf.Database.ConnectionString)
-	if err != nil {
-		log.Fatalf("failed to connect to db: %v", err)
+// checkSchema compares the database against the embedded migrations according to mode.
+func checkSchema(dbConn *sql.DB, mode string) error {
+	if mode == "" {
+		mode = migrate.ModeCheck
+	}
+	if mode == migrate.ModeOff {
+		return nil
 	}
-	defer dbConn.Close()
 
-	addr := conf.Server.Addr
-	if addr == "" {
-		addr = ":8080"
+	m, err := migrate.New(dbConn, migrations.FS)
+	if err != nil {
+		return err
 	}
 
-	if err := server.Run(addr, dbConn); err != nil {
-		log.Fatalf("server stopped with error: %v", err)
+	ctx := context.Background()
+	switch mode {
+	case migrate.ModeAuto:
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			slog.Info("applied migration", "version", mig.Version, "name", mig.Name)
+		}
+		return err
+	case migrate.ModeCheck:
+		if err := m.EnsureUpToDate(ctx); err != nil {
+			return fmt.Errorf("%w (run `users migrate up`, after `users migrate baseline -version 2` if the database predates schema_migrations, or set MigrationMode to %q)", err, migrate.ModeAuto)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown MigrationMode %q (use %q, %q or %q)", mode, migrate.ModeCheck, migrate.ModeAuto, migrate.ModeOff)
 	}
 }
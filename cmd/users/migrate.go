@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/example/golang-project/migrations"
+	"github.com/example/golang-project/pkg/db/migrate"
+)
+
+const migrateUsage = `usage: users migrate <command> [flags]
+
+commands:
+  up              apply all pending migrations
+  down [-steps N] revert the last N applied migrations (default 1)
+  status          list migrations and whether they are applied
+  redo            revert and re-apply the last applied migration
+  baseline -version N
+                  record migrations up to N as applied without running them
+                  (for databases created before schema_migrations existed)`
+
+// runMigrate implements the `users migrate` subcommand against the embedded migrations.
+func runMigrate(dbConn *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	m, err := migrate.New(dbConn, migrations.FS)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %03d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+
+	case "down":
+		flags := flag.NewFlagSet("down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return fmt.Errorf("-steps must be at least 1")
+		}
+		reverted, err := m.Down(ctx, *steps)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %03d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+
+	case "redo":
+		mig, err := m.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("redid %03d_%s\n", mig.Version, mig.Name)
+		return nil
+
+	case "baseline":
+		flags := flag.NewFlagSet("baseline", flag.ContinueOnError)
+		version := flags.Int64("version", 0, "last migration already present in the database")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *version < 1 {
+			return fmt.Errorf("-version is required")
+		}
+		recorded, err := m.Baseline(ctx, *version)
+		for _, mig := range recorded {
+			fmt.Printf("recorded %03d_%s as applied\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(recorded) == 0 {
+			fmt.Printf("migrations up to %03d are already recorded\n", *version)
+		}
+		return err
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state, appliedAt := "pending", ""
+			if s.Applied {
+				state = "applied"
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05Z07:00")
+			}
+			if s.ChecksumMismatch {
+				state = "modified"
+			}
+			fmt.Fprintf(tw, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+}
@@ -16,36 +16,102 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/members": {
+        "/audit": {
             "get": {
-                "description": "Retrieve all church members from the database, ordered by join date (newest first)",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of audit entries across members and users, newest first unless sort is given.\n\nFilters use filter[field][operator]=value (filter[field]=value means eq) and are combined with AND.\nFields and operators: id, entity_id, actor_id, created_at (eq, ne, gt, gte, lt, lte); entity (member, user), action (create, update, delete, restore, merge, purge) (eq, ne);\nactor_email (eq, ne, prefix, suffix, contains); request_id (eq). sort accepts id and created_at; prefix a field with - for descending.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "members"
+                    "audit"
+                ],
+                "summary": "Query the audit trail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. filter[action]=delete",
+                        "name": "filter[field][operator]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, e.g. created_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of matching entries",
+                        "name": "include_total",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all church members",
                 "responses": {
                     "200": {
-                        "description": "List of members",
+                        "description": "Page of audit entries",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ChurchMember"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_AuditEntry"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown filter field or operator, invalid value, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/login": {
             "post": {
-                "description": "Create a new church member with name, email, and optional biography",
+                "description": "Exchange email and password for a signed JWT access token",
                 "consumes": [
                     "application/json"
                 ],
@@ -53,495 +119,2983 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "members"
+                    "auth"
                 ],
-                "summary": "Create a new church member",
+                "summary": "Log in",
                 "parameters": [
                     {
-                        "description": "Church member data",
-                        "name": "member",
+                        "description": "Email and password",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.ChurchMember"
+                            "$ref": "#/definitions/model.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Member created",
+                    "200": {
+                        "description": "Access token",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer",
-                                "format": "int64"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.TokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or validation error",
+                        "description": "Invalid request body",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid email or password",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
             }
         },
-        "/members/joined": {
+        "/auth/me": {
             "get": {
-                "description": "Retrieve church members joined within a specific date range",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the identity carried by the access token",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "members"
-                ],
-                "summary": "List church members by joined date range",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end",
-                        "in": "query",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Current caller",
                 "responses": {
                     "200": {
-                        "description": "List of members",
+                        "description": "Caller identity",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ChurchMember"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/auth.Identity"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid date format",
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is up; performs no dependency checks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Process alive",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.HealthReport"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/members/{id}": {
+        "/members": {
             "get": {
-                "description": "Retrieve a church member by their ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of church members, ordered by join date (newest first) unless sort is given. Pass next_cursor or prev_cursor from the previous page as cursor to move through the list.\n\nFilters use filter[field][operator]=value (filter[field]=value means eq) and are combined with AND.\nFields and operators: id, joined_at, created_at, updated_at (eq, ne, gt, gte, lt, lte; dates as YYYY-MM-DD or RFC 3339);\nname, email (eq, ne, prefix, suffix, contains; case-insensitive); phone, address (same plus exists=true|false); biography (contains, exists).\nsort is a comma-separated list of id, name, email, joined_at, created_at, updated_at; prefix a field with - for descending.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "members"
                 ],
-                "summary": "Get church member by ID",
+                "summary": "List church members",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. filter[email][suffix]=@example.com",
+                        "name": "filter[field][operator]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, e.g. -updated_at,name",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "format": "int64",
-                        "description": "Member ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of members",
+                        "name": "include_total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Member data",
+                        "description": "Page of members",
                         "schema": {
-                            "$ref": "#/definitions/model.ChurchMember"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Unknown filter field or operator, invalid value, sort, limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
-                    "404": {
-                        "description": "Member not found",
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update church member information including name, email, phone, address, and biography",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new church member with name, email, and optional biography",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "members"
                 ],
-                "summary": "Update a church member",
+                "summary": "Create a new church member",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Member ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated member data",
+                        "description": "Church member data",
                         "name": "member",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "$ref": "#/definitions/model.ChurchMember"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; a repeat replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No content",
+                    "201": {
+                        "description": "Member created",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer",
+                                                "format": "int64"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the new member"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is a replay"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid request body or validation error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
-                    "404": {
-                        "description": "Member not found",
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists, or Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete church member by ID",
+            }
+        },
+        "/members/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Score pairs of members by normalized name similarity, phone, address and email name, and return pairs at or above min_score, best first.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "members"
                 ],
-                "summary": "Delete a church member",
+                "summary": "List likely duplicate members",
                 "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Minimum score between 0 and 1 (default 0.6)",
+                        "name": "min_score",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "format": "int64",
-                        "description": "Member ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Maximum pairs to return (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No content",
+                    "200": {
+                        "description": "Duplicate candidates",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.DuplicateCandidate"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid min_score or limit",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/members/export": {
             "get": {
-                "description": "Retrieve all users from the database",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download every member matching the filters as CSV, an Excel workbook (xlsx) or vCard 4.0 contacts (vcf). filter[...] and sort work exactly as in GET /members, without paging.\ncolumns picks the fields and their order from id, name, email, phone, address, biography, joined_at, created_at and updated_at (default: id, name, email, phone, address, joined_at).\nvCards always carry the name and map email, phone, address, biography and updated_at to EMAIL, TEL, ADR, NOTE and REV; other columns are skipped.\nThe file is streamed as it is read from the database. If the database fails midway the connection is closed before the download completes.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "text/vcard"
                 ],
                 "tags": [
-                    "users"
+                    "members"
+                ],
+                "summary": "Export church members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv, xlsx or vcf",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields, e.g. name,email,phone",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. filter[name][prefix]=jo",
+                        "name": "filter[field][operator]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, e.g. -joined_at,name",
+                        "name": "sort",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all users",
                 "responses": {
                     "200": {
-                        "description": "List of users",
+                        "description": "Exported members",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.User"
-                            }
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown format, column, filter field or operator, invalid value or sort",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/members/import": {
             "post": {
-                "description": "Create a new user with name and email",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upsert members from a CSV file, sent as the request body (Content-Type text/csv) or as the \"file\" field of a multipart form.\nThe first row names the columns. By default a column feeds the member field of the same name (name, email, phone, address, biography, joined_at);\ncolumns[field]=Header maps a differently named column. A row whose email matches a live member updates that member's mapped fields; other rows create members.\nEvery row is validated like POST /members; invalid rows are skipped and listed in the report. Rows are committed in batches, one transaction per batch.\nWith dry_run=true nothing is saved but the report shows what would happen. With format=csv the report's errors are returned as a CSV download.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "multipart/form-data"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "users"
+                    "members"
                 ],
-                "summary": "Create a new user",
+                "summary": "Import church members from CSV",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.User"
-                        }
+                        "type": "file",
+                        "description": "CSV file, when sent as multipart/form-data",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSV header to read a member field from, e.g. columns[name]=Full Name",
+                        "name": "columns[field]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the file without saving anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows per transaction (1-1000, default 100)",
+                        "name": "batch_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv to download the errors as CSV instead of JSON",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; a repeat replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User created",
+                    "200": {
+                        "description": "Import report",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer",
-                                "format": "int64"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is a replay"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid options, missing email column or file too large",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is neither CSV nor a multipart form",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/members/joined": {
             "get": {
-                "description": "Retrieve a user by their ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of church members joined within a specific date range, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "members"
                 ],
-                "summary": "Get user by ID",
+                "summary": "List church members by joined date range",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Extra filter, same syntax as GET /members",
+                        "name": "filter[field][operator]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, same syntax as GET /members",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of matching members",
+                        "name": "include_total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User data",
+                        "description": "Page of members",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid date, filter, sort, limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update user name and email by ID",
-                "consumes": [
+            }
+        },
+        "/members/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search over name, email, address and biography. Every word is matched as a prefix and all words must match.\nResults are ordered by relevance; matching text is returned in highlight with terms wrapped in \u003cmark\u003e (not HTML-escaped).",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "members"
                 ],
-                "summary": "Update a user",
+                "summary": "Search church members",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Search text (max 200 characters)",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Updated user data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page of the same search",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of matches",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of search hits",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_MemberSearchHit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Missing q, invalid limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of deleted church members, most recently deleted first. They can be restored until the retention period ends and they are purged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "List deleted church members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of deleted members",
+                        "name": "include_total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No content",
+                    "200": {
+                        "description": "Page of deleted members",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete user by ID",
+            }
+        },
+        "/members/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a church member by their ID",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "users"
+                    "members"
                 ],
-                "summary": "Delete a user",
+                "summary": "Get church member by ID",
                 "parameters": [
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "User ID",
+                        "description": "Member ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No content",
+                    "200": {
+                        "description": "Member data",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Member version; send it as If-Match on PUT, PATCH and DELETE"
+                            }
+                        }
+                    },
+                    "301": {
+                        "description": "Member was merged; Location points at the survivor",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer",
+                                                "format": "int64"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Invalid ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ResponseModel"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "model.ChurchMember": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "biography": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "joined_at": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.User": {
-            "type": "object",
-            "required": [
-                "email",
-                "name"
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update church member information including name, email, phone, address, and biography.\nIf-Match must carry the ETag from GET; if the member changed since, the response is 412 with the current member and ETag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Update a church member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated member data",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ChurchMember"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New member version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "412": {
+                        "description": "Member was modified; result holds the current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a church member to the trash. It can be restored with POST /members/{id}/restore until the retention period ends.\nIf-Match must carry the ETag from GET; if the member changed since, the response is 412 with the current member and ETag.",
+                "tags": [
+                    "members"
+                ],
+                "summary": "Delete a church member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "412": {
+                        "description": "Member was modified; result holds the current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change only the fields in the body. With Content-Type application/merge-patch+json the body is a JSON Merge Patch (RFC 7396),\ne.g. {\"phone\":\"555-0100\",\"address\":null} sets phone and clears address. With application/json-patch+json it is a JSON Patch (RFC 6902),\ne.g. [{\"op\":\"test\",\"path\":\"/email\",\"value\":\"a@example.com\"},{\"op\":\"replace\",\"path\":\"/name\",\"value\":\"Ann\"}].\nThe patched member is validated like a PUT body and only changed columns are written. id, created_at, version and the like are read-only.\nIf-Match must carry the ETag from GET; if the member changed since, the response is 412 with the current member and ETag.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Partially update a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being patched, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or array of JSON Patch operations",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patched member",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New member version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, malformed patch or invalid result",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists or a test operation failed",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "412": {
+                        "description": "Member was modified; result holds the current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type is not a patch format",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of the audit entries of a church member, newest first: who made each change, in which request, and the old and new value of every changed field.\nHistory is kept after the member is deleted or purged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Change history of a church member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of entries",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of audit entries",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_AuditEntry"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Combine duplicate_id into the member in the path in one transaction. Fields keep the survivor's value unless it is empty or prefer names \"duplicate\" for them; joined_at keeps the earlier date.\nThe duplicate moves to the trash and GET /members/{duplicate_id} redirects to the survivor afterwards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Merge a duplicate into a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Surviving member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate to fold in and per-field preferences",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; a repeat replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged member",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is a replay"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "Member or duplicate not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists, or Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a church member out of the trash. Fails with 409 if another member has taken the email since. Restoring a merged duplicate removes its redirect to the survivor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Restore a deleted church member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; a repeat replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored member",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.ChurchMember"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is a replay"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists, or Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Pings the database and checks the schema is at the embedded migration version. Fails once shutdown has begun.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.HealthReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.HealthReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of users ordered by ID. Pass next_cursor or prev_cursor from the previous page as cursor to move through the list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of users",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new user with name, email, password and optional role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; a repeat replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer",
+                                                "format": "int64"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the new user"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is a replay"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists, or Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one page of deleted users, most recently deleted first. They can be restored until the retention period ends and they are purged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List deleted users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return the total number of deleted users",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of deleted users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.Page-model_User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a user by their ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "User version; send it as If-Match on PUT, PATCH and DELETE"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user name, email and optionally role or password by ID.\nIf-Match must carry the ETag from GET; if the user changed since, the response is 412 with the current user and ETag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated user data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New user version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "412": {
+                        "description": "User was modified; result holds the current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a user to the trash; a deleted user can no longer log in. It can be restored with POST /users/{id}/restore until the retention period ends.\nIf-Match must carry the ETag from GET; if the user changed since, the response is 412 with the current user and ETag.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "412": {
+                        "description": "User was modified; result holds the current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change only the fields in the body. With Content-Type application/merge-patch+json the body is a JSON Merge Patch (RFC 7396),\ne.g. {\"phone\":\"555-0100\",\"address\":null} sets phone and clears address. With application/json-patch+json it is a JSON Patch (RFC 6902),\ne.g. [{\"op\":\"test\",\"path\":\"/email\",\"value\":\"a@example.com\"},{\"op\":\"replace\",\"path\":\"/name\",\"value\":\"Ann\"}].\nThe patched user is validated like a PUT body and only changed columns are written. id, created_at, version and the like are read-only.\nIf-Match must carry the ETag from GET; if the user changed since, the response is 412 with the current user and ETag.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Partially update a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being patched, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or array of JSON Patch operations",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patched user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New user version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, malformed patch or invalid result",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists or a test operation failed",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "412": {
+                        "description": "User was modified; result holds the current version",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type is not a patch format",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a user out of the trash. Fails with 409 if another user has taken the email since.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Restore a deleted user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes the request safe to retry; a repeat replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response is a replay"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "403": {
+                        "description": "Role lacks the required permission",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "404": {
+                        "description": "User not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already exists, or Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key already used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResponseModel"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperror.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.Identity": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/auth.Role"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "staff",
+                "member",
+                "viewer",
+                "member"
+            ],
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RoleStaff",
+                "RoleMember",
+                "RoleViewer",
+                "DefaultRole"
+            ]
+        },
+        "model.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, update, delete, restore, merge or purge",
+                    "type": "string"
+                },
+                "actor_email": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "description": "unset for background jobs",
+                    "type": "integer"
+                },
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.FieldChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity": {
+                    "description": "member or user",
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ChurchMember": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "biography": {
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "set only on members listed from the trash",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 2
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write; served as the ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DuplicateCandidate": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "$ref": "#/definitions/model.ChurchMember"
+                },
+                "member": {
+                    "$ref": "#/definitions/model.ChurchMember"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.FieldChange": {
+            "type": "object",
+            "properties": {
+                "new": {},
+                "old": {}
+            }
+        },
+        "model.HealthCheck": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.HealthReport": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HealthCheck"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperror.FieldError"
+                    }
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MemberHighlight": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "biography": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MemberSearchHit": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
             ],
             "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "biography": {
+                    "type": "string",
+                    "maxLength": 5000
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "set only on members listed from the trash",
+                    "type": "string"
+                },
                 "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "highlight": {
+                    "$ref": "#/definitions/model.MemberHighlight"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 2
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented by every write; served as the ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MergeRequest": {
+            "type": "object",
+            "required": [
+                "duplicate_id"
+            ],
+            "properties": {
+                "duplicate_id": {
+                    "type": "integer"
+                },
+                "prefer": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.Page-model_AuditEntry": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditEntry"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Page-model_ChurchMember": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ChurchMember"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Page-model_MemberSearchHit": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MemberSearchHit"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Page-model_User": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.User"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ResponseModel": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "\"00\" = success, \"01\" = error",
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "stable machine-readable code, e.g. \"MEMBER_NOT_FOUND\"",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors lists every invalid field when ErrorCode is VALIDATION_FAILED.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperror.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "result": {},
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.User": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "set only on users listed from the trash",
                     "type": "string"
                 },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -549,9 +3103,36 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 255,
                     "minLength": 1
+                },
+                "password": {
+                    "description": "write-only: accepted on create/update, never returned; at most 72 bytes (bcrypt)",
+                    "type": "string",
+                    "minLength": 8
+                },
+                "role": {
+                    "description": "see auth.Role; empty means member on create, unchanged on update",
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "staff",
+                        "member",
+                        "viewer"
+                    ]
+                },
+                "version": {
+                    "description": "incremented by every write; served as the ETag",
+                    "type": "integer"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and the access token from POST /auth/login.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -7,6 +7,7 @@ require (
 	github.com/lib/pq v1.10.6
 	github.com/swaggo/http-swagger v1.3.4
 	github.com/swaggo/swag v1.16.6
+	golang.org/x/crypto v0.48.0
 )
 
 require (
@@ -0,0 +1,172 @@
+// Package apperror defines the typed errors returned by services. Each error has a Kind,
+// which decides the HTTP status, and a stable Code that clients can switch on.
+// Codes are part of the API contract: add new ones freely but never rename an existing one.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind classifies an error independently of its message.
+type Kind string
+
+// Error kinds, mapped to HTTP statuses by HTTPStatus.
+const (
+	KindValidation   Kind = "validation"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindInternal     Kind = "internal"
+
+	// KindPreconditionFailed means the caller's If-Match no longer names the current version.
+	KindPreconditionFailed Kind = "precondition_failed"
+	// KindPreconditionRequired means a conditional request header is missing.
+	KindPreconditionRequired Kind = "precondition_required"
+	// KindUnsupportedMediaType means the request body's Content-Type is not accepted.
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+	// KindUnprocessable means the request is well formed but cannot be carried out as sent,
+	// e.g. an Idempotency-Key reused for a different request.
+	KindUnprocessable Kind = "unprocessable"
+)
+
+// Codes shared across packages. Domain-specific codes live next to the service that returns them.
+const (
+	CodeInvalidBody     = "INVALID_BODY"
+	CodeInvalidID       = "INVALID_ID"
+	CodeValidation      = "VALIDATION_FAILED"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeInvalidToken    = "INVALID_TOKEN"
+	CodeForbidden       = "FORBIDDEN"
+	CodeInternal        = "INTERNAL_ERROR"
+	CodeIfMatchRequired = "IF_MATCH_REQUIRED"
+	CodeInvalidPatch    = "INVALID_PATCH"
+	CodeUnsupportedType = "UNSUPPORTED_MEDIA_TYPE"
+)
+
+// FieldError describes one invalid input field. Code is the failing rule, e.g. "required" or "max".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error with a kind, a stable code and a client-safe message.
+// Err optionally holds the underlying cause, which is logged but never sent to clients.
+// Fields lists every invalid input field for validation errors.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+	Fields  []FieldError
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is matches any *Error with the same Code, so package-level errors can be used with errors.Is
+// even when a copy with a different message or cause is returned.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// New creates an Error.
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Wrap creates an Error that keeps err as its cause.
+func Wrap(err error, kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Err: err}
+}
+
+// Validation reports invalid input (400).
+func Validation(code, message string) *Error { return New(KindValidation, code, message) }
+
+// InvalidFields reports one or more invalid input fields (400) with the VALIDATION_FAILED code.
+func InvalidFields(fields []FieldError) *Error {
+	e := New(KindValidation, CodeValidation, "validation failed")
+	e.Fields = fields
+	return e
+}
+
+// NotFound reports a missing resource (404).
+func NotFound(code, message string) *Error { return New(KindNotFound, code, message) }
+
+// Conflict reports a clash with existing state, e.g. a duplicate email (409).
+func Conflict(code, message string) *Error { return New(KindConflict, code, message) }
+
+// Unauthorized reports missing or invalid credentials (401).
+func Unauthorized(code, message string) *Error { return New(KindUnauthorized, code, message) }
+
+// Forbidden reports an authenticated caller lacking permission (403).
+func Forbidden(code, message string) *Error { return New(KindForbidden, code, message) }
+
+// PreconditionFailed reports a write against a version the caller no longer holds (412).
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPreconditionFailed, code, message)
+}
+
+// PreconditionRequired reports a write sent without the required If-Match header (428).
+func PreconditionRequired(code, message string) *Error {
+	return New(KindPreconditionRequired, code, message)
+}
+
+// UnsupportedMediaType reports a request body in a format the endpoint does not accept (415).
+func UnsupportedMediaType(code, message string) *Error {
+	return New(KindUnsupportedMediaType, code, message)
+}
+
+// Unprocessable reports a well-formed request that cannot be carried out as sent (422).
+func Unprocessable(code, message string) *Error { return New(KindUnprocessable, code, message) }
+
+// Internal wraps an unexpected error (500). Its message is generic on purpose.
+func Internal(err error) *Error {
+	return Wrap(err, KindInternal, CodeInternal, "internal server error")
+}
+
+// As returns err as an *Error. Errors that aren't typed are treated as internal.
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal(err)
+}
+
+// HTTPStatus maps a kind to its HTTP status code.
+func HTTPStatus(kind Kind) int {
+	switch kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	case KindUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
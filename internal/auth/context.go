@@ -0,0 +1,24 @@
+package auth
+
+import "context"
+
+// Identity is the authenticated caller, taken from a validated access token.
+type Identity struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+	Name   string `json:"name,omitempty"`
+	Role   Role   `json:"role"`
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the authenticated caller, or nil for anonymous requests.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
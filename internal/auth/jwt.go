@@ -0,0 +1,157 @@
+// Package auth issues and validates JWT access tokens, hashes passwords and carries the
+// authenticated caller through context.Context (similar to .NET's ClaimsPrincipal).
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minSecretLength is the shortest HMAC key accepted for HS256 (256 bits).
+const minSecretLength = 32
+
+// placeholderSecrets are example secrets that were once committed to this repository.
+// Anyone can read them, so NewTokenManager refuses them like a short key.
+var placeholderSecrets = []string{
+	"dev-only-secret-change-me-0123456789abcdef",
+}
+
+// clockSkew tolerates small clock differences between token issuer and validator.
+const clockSkew = 30 * time.Second
+
+// ErrInvalidToken is returned for malformed, forged or expired tokens.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims is the JWT payload issued by this service.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Email     string `json:"email"`
+	Name      string `json:"name,omitempty"`
+	Role      Role   `json:"role"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenManager signs and verifies HS256 JWT access tokens.
+type TokenManager struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewTokenManager creates a TokenManager. secret is required, must be at least 32 bytes
+// and must not be one of the placeholders published in this repository.
+func NewTokenManager(secret, issuer string, ttl time.Duration) (*TokenManager, error) {
+	if secret == "" {
+		return nil, errors.New("jwt secret is not set; set JWT_SECRET")
+	}
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("jwt secret must be at least %d bytes", minSecretLength)
+	}
+	for _, p := range placeholderSecrets {
+		if strings.EqualFold(secret, p) {
+			return nil, errors.New("jwt secret is the example value from this repository; set JWT_SECRET to a random value")
+		}
+	}
+	if ttl <= 0 {
+		return nil, errors.New("token ttl must be positive")
+	}
+	return &TokenManager{secret: []byte(secret), issuer: issuer, ttl: ttl, now: time.Now}, nil
+}
+
+// Issue returns a signed access token for id and its expiry time.
+func (tm *TokenManager) Issue(id Identity) (string, time.Time, error) {
+	now := tm.now().UTC()
+	expiresAt := now.Add(tm.ttl)
+	claims := Claims{
+		Subject:   strconv.FormatInt(id.UserID, 10),
+		Email:     id.Email,
+		Name:      id.Name,
+		Role:      id.Role,
+		Issuer:    tm.issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	return signingInput + "." + encodeSegment(tm.sign(signingInput)), expiresAt, nil
+}
+
+// Parse verifies token's signature, issuer and expiry and returns the caller's identity.
+func (tm *TokenManager) Parse(token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	headerJSON, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	// Only HS256 is accepted; in particular "none" and asymmetric algorithms are rejected.
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := decodeSegment(parts[2])
+	if err != nil || !hmac.Equal(sig, tm.sign(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := tm.now()
+	if claims.Issuer != tm.issuer ||
+		now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)) ||
+		now.Add(clockSkew).Before(time.Unix(claims.IssuedAt, 0)) {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || userID <= 0 {
+		return nil, ErrInvalidToken
+	}
+	return &Identity{UserID: userID, Email: claims.Email, Name: claims.Name, Role: claims.Role}, nil
+}
+
+func (tm *TokenManager) sign(signingInput string) []byte {
+	mac := hmac.New(sha256.New, tm.secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
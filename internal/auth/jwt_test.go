@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTokenManagerSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{
+		{"empty", "", "not set"},
+		{"too short", strings.Repeat("x", minSecretLength-1), "at least 32 bytes"},
+		{"placeholder", "dev-only-secret-change-me-0123456789abcdef", "example value"},
+		{"placeholder in other case", "DEV-ONLY-SECRET-CHANGE-ME-0123456789ABCDEF", "example value"},
+		{"random", "q3Jr8vN0cXk2pLw7ZsYt5bHm1eUa9dGf", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm, err := NewTokenManager(tt.secret, "test", time.Minute)
+			if tt.wantErr == "" {
+				if err != nil || tm == nil {
+					t.Fatalf("NewTokenManager: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	tm, err := NewTokenManager(strings.Repeat("k", minSecretLength), "test", time.Minute)
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	token, _, err := tm.Issue(Identity{UserID: 7, Email: "a@example.com", Role: RoleAdmin})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if id, err := tm.Parse(token); err != nil || id.UserID != 7 || id.Role != RoleAdmin {
+		t.Fatalf("Parse = %+v, %v", id, err)
+	}
+	other, _ := NewTokenManager(strings.Repeat("j", minSecretLength), "test", time.Minute)
+	if _, err := other.Parse(token); err != ErrInvalidToken {
+		t.Errorf("token signed with another secret: err = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+// Role is a user's role, stored in users.role and carried in the access token.
+type Role string
+
+// Roles, from most to least privileged.
+const (
+	RoleAdmin  Role = "admin"
+	RoleStaff  Role = "staff"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+// DefaultRole is assigned to users created without an explicit role.
+const DefaultRole = RoleMember
+
+// Permission is an action a route may require, in resource:verb form.
+type Permission string
+
+// Permissions checked by the routes in server.Run.
+const (
+	PermMembersRead   Permission = "members:read"
+	PermMembersWrite  Permission = "members:write"
+	PermMembersDelete Permission = "members:delete"
+	PermMembersMerge  Permission = "members:merge"
+	PermMembersImport Permission = "members:import"
+	PermMembersExport Permission = "members:export"
+	PermUsersRead     Permission = "users:read"
+	PermUsersWrite    Permission = "users:write"
+	PermUsersDelete   Permission = "users:delete"
+	PermAuditRead     Permission = "audit:read"
+)
+
+// rolePermissions is the permission matrix. Roles not listed here have no permissions.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermMembersRead, PermMembersWrite, PermMembersDelete, PermMembersMerge, PermMembersImport, PermMembersExport,
+		PermUsersRead, PermUsersWrite, PermUsersDelete,
+		PermAuditRead,
+	},
+	RoleStaff: {
+		PermMembersRead, PermMembersWrite, PermMembersDelete, PermMembersMerge, PermMembersImport, PermMembersExport,
+		PermUsersRead,
+	},
+	RoleMember: {
+		PermMembersRead,
+		PermUsersRead,
+	},
+	RoleViewer: {
+		PermMembersRead,
+	},
+}
+
+// ValidRole reports whether r is one of the known roles.
+func ValidRole(r Role) bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// HasPermission reports whether role r grants p.
+func HasPermission(r Role, p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether the identity's role grants p; a nil identity has no permissions.
+func (id *Identity) Can(p Permission) bool {
+	return id != nil && HasPermission(id.Role, p)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/internal/service"
+)
+
+// AuditHandler wires HTTP requests to the AuditService.
+type AuditHandler struct {
+	svc *service.AuditService
+}
+
+// NewAuditHandler creates a new handler with the given service.
+func NewAuditHandler(svc *service.AuditService) *AuditHandler {
+	return &AuditHandler{svc: svc}
+}
+
+// MemberHistoryHandler handles GET /members/{id}/history?limit=20&cursor=...
+// @Summary Change history of a church member
+// @Description Retrieve one page of the audit entries of a church member, newest first: who made each change, in which request, and the old and new value of every changed field.
+// @Description History is kept after the member is deleted or purged.
+// @Tags members
+// @Produce json
+// @Param id path int64 true "Member ID"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of entries"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.AuditEntry]} "Page of audit entries"
+// @Failure 400 {object} model.ResponseModel "Invalid ID, limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/{id}/history [get]
+func (h *AuditHandler) MemberHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	page, err := pageRequest(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	var list *model.Page[*model.AuditEntry]
+	list, err = h.svc.MemberHistory(r.Context(), id, page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, list)
+}
+
+// ListAuditHandler handles GET /audit?filter[entity]=member&filter[actor_id]=3&filter[created_at][gte]=2024-01-01&limit=20&cursor=...
+// @Summary Query the audit trail
+// @Description Retrieve one page of audit entries across members and users, newest first unless sort is given.
+// @Description
+// @Description Filters use filter[field][operator]=value (filter[field]=value means eq) and are combined with AND.
+// @Description Fields and operators: id, entity_id, actor_id, created_at (eq, ne, gt, gte, lt, lte); entity (member, user), action (create, update, delete, restore, merge, purge) (eq, ne);
+// @Description actor_email (eq, ne, prefix, suffix, contains); request_id (eq). sort accepts id and created_at; prefix a field with - for descending.
+// @Tags audit
+// @Produce json
+// @Param filter[field][operator] query string false "Filter, e.g. filter[action]=delete"
+// @Param sort query string false "Sort order, e.g. created_at"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of matching entries"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.AuditEntry]} "Page of audit entries"
+// @Failure 400 {object} model.ResponseModel "Unknown filter field or operator, invalid value, sort, limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /audit [get]
+func (h *AuditHandler) ListAuditHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := listQuery(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	page, err := pageRequest(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	list, err := h.svc.ListEntries(r.Context(), q, page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, list)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/internal/service"
+)
+
+// AuthHandler wires HTTP requests to the AuthService.
+type AuthHandler struct {
+	svc *service.AuthService
+}
+
+// NewAuthHandler creates a new handler with the given service.
+func NewAuthHandler(svc *service.AuthService) *AuthHandler {
+	return &AuthHandler{svc: svc}
+}
+
+// LoginHandler handles POST /auth/login
+// @Summary Log in
+// @Description Exchange email and password for a signed JWT access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.LoginRequest true "Email and password"
+// @Success 200 {object} model.ResponseModel{result=model.TokenResponse} "Access token"
+// @Failure 400 {object} model.ResponseModel "Invalid request body"
+// @Failure 401 {object} model.ResponseModel "Invalid email or password"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Router /auth/login [post]
+func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var in model.LoginRequest
+	if err := decodeJSON(r, &in); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	token, err := h.svc.Login(r.Context(), in.Email, in.Password)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respond.JSON(w, http.StatusOK, token)
+}
+
+// MeHandler handles GET /auth/me
+// @Summary Current caller
+// @Description Return the identity carried by the access token
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.ResponseModel{result=auth.Identity} "Caller identity"
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Router /auth/me [get]
+func (h *AuthHandler) MeHandler(w http.ResponseWriter, r *http.Request) {
+	id := auth.FromContext(r.Context())
+	if id == nil {
+		respond.Error(w, r, apperror.Unauthorized(apperror.CodeUnauthenticated, "authentication required"))
+		return
+	}
+	respond.JSON(w, http.StatusOK, id)
+}
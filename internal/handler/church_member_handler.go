@@ -1,15 +1,16 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/gorilla/mux"
-
+	"github.com/example/golang-project/internal/apperror"
 	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
 	"github.com/example/golang-project/internal/service"
+	"github.com/example/golang-project/internal/validation"
 )
 
 // ChurchMemberHandler wires HTTP requests to the ChurchMemberService.
@@ -29,24 +30,31 @@ func NewChurchMemberHandler(svc *service.ChurchMemberService) *ChurchMemberHandl
 // @Accept json
 // @Produce json
 // @Param member body model.ChurchMember true "Church member data"
-// @Success 201 {object} map[string]int64 "Member created"
-// @Failure 400 {string} string "Invalid request body or validation error"
-// @Failure 500 {string} string "Internal server error"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; a repeat replays the first response"
+// @Success 201 {object} model.ResponseModel{result=map[string]int64} "Member created"
+// @Header 201 {string} ETag "Version of the new member"
+// @Header 201 {string} Idempotent-Replayed "true when the response is a replay"
+// @Failure 400 {object} model.ResponseModel "Invalid request body or validation error"
+// @Failure 409 {object} model.ResponseModel "Email already exists, or Idempotency-Key still in use"
+// @Failure 422 {object} model.ResponseModel "Idempotency-Key already used for a different request"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /members [post]
 func (h *ChurchMemberHandler) CreateMemberHandler(w http.ResponseWriter, r *http.Request) {
 	var in model.ChurchMember
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(r, &in); err != nil {
+		respond.Error(w, r, err)
 		return
 	}
 	id, err := h.svc.CreateMember(r.Context(), &in)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]int64{"id": id})
+	setETag(w, in.Version)
+	respond.JSON(w, http.StatusCreated, map[string]int64{"id": id})
 }
 
 // GetMemberHandler handles GET /members/{id}
@@ -55,158 +63,471 @@ func (h *ChurchMemberHandler) CreateMemberHandler(w http.ResponseWriter, r *http
 // @Tags members
 // @Produce json
 // @Param id path int64 true "Member ID"
-// @Success 200 {object} model.ChurchMember "Member data"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Member not found"
-// @Failure 500 {string} string "Internal server error"
+// @Success 200 {object} model.ResponseModel{result=model.ChurchMember} "Member data"
+// @Header 200 {string} ETag "Member version; send it as If-Match on PUT, PATCH and DELETE"
+// @Success 301 {object} model.ResponseModel{result=map[string]int64} "Member was merged; Location points at the survivor"
+// @Failure 400 {object} model.ResponseModel "Invalid ID"
+// @Failure 404 {object} model.ResponseModel "Member not found"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /members/{id} [get]
 func (h *ChurchMemberHandler) GetMemberHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
 	m, err := h.svc.GetMember(r.Context(), id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if errors.Is(err, service.ErrMemberNotFound) {
+		// A member merged into another keeps resolving to the survivor.
+		if survivor, rerr := h.svc.MergedInto(r.Context(), id); rerr == nil && survivor > 0 {
+			location := "/members/" + strconv.FormatInt(survivor, 10)
+			w.Header().Set("Location", location)
+			resp := model.NewErrorResponse("member was merged into " + location)
+			resp.ErrorCode = "MEMBER_MERGED"
+			resp.Result = map[string]int64{"id": survivor}
+			respond.Envelope(w, http.StatusMovedPermanently, resp)
+			return
+		}
 	}
-	if m == nil {
-		http.NotFound(w, r)
+	if err != nil {
+		respond.Error(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(m)
+	setETag(w, m.Version)
+	respond.JSON(w, http.StatusOK, m)
 }
 
 // UpdateMemberHandler handles PUT /members/{id}
 // @Summary Update a church member
-// @Description Update church member information including name, email, phone, address, and biography
+// @Description Update church member information including name, email, phone, address, and biography.
+// @Description If-Match must carry the ETag from GET; if the member changed since, the response is 412 with the current member and ETag.
 // @Tags members
 // @Accept json
 // @Param id path int64 true "Member ID"
+// @Param If-Match header string true "ETag of the version being updated, or *"
 // @Param member body model.ChurchMember true "Updated member data"
 // @Success 204 {string} string "No content"
-// @Failure 400 {string} string "Invalid request"
-// @Failure 404 {string} string "Member not found"
-// @Failure 500 {string} string "Internal server error"
+// @Header 204 {string} ETag "New member version"
+// @Failure 400 {object} model.ResponseModel "Invalid request"
+// @Failure 404 {object} model.ResponseModel "Member not found"
+// @Failure 409 {object} model.ResponseModel "Email already exists"
+// @Failure 412 {object} model.ResponseModel{result=model.ChurchMember} "Member was modified; result holds the current version"
+// @Failure 428 {object} model.ResponseModel "If-Match header missing"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /members/{id} [put]
 func (h *ChurchMemberHandler) UpdateMemberHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
 	var in model.ChurchMember
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(r, &in); err != nil {
+		respond.Error(w, r, err)
 		return
 	}
 	in.ID = id
+	if in.Version, err = ifMatch(r); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
 	if err := h.svc.UpdateMember(r.Context(), &in); err != nil {
-		if err.Error() == "member not found" {
-			http.NotFound(w, r)
+		h.writeError(w, r, id, err)
+		return
+	}
+	setETag(w, in.Version)
+	respond.NoContent(w)
+}
+
+// writeError answers a failed If-Match with 412 carrying the member's current state and
+// ETag, and any other error as usual.
+func (h *ChurchMemberHandler) writeError(w http.ResponseWriter, r *http.Request, id int64, err error) {
+	if errors.Is(err, service.ErrMemberModified) {
+		if current, gerr := h.svc.GetMember(r.Context(), id); gerr == nil {
+			setETag(w, current.Version)
+			respond.ErrorResult(w, r, err, current)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+	respond.Error(w, r, err)
+}
+
+// PatchMemberHandler handles PATCH /members/{id}
+// @Summary Partially update a member
+// @Description Change only the fields in the body. With Content-Type application/merge-patch+json the body is a JSON Merge Patch (RFC 7396),
+// @Description e.g. {"phone":"555-0100","address":null} sets phone and clears address. With application/json-patch+json it is a JSON Patch (RFC 6902),
+// @Description e.g. [{"op":"test","path":"/email","value":"a@example.com"},{"op":"replace","path":"/name","value":"Ann"}].
+// @Description The patched member is validated like a PUT body and only changed columns are written. id, created_at, version and the like are read-only.
+// @Description If-Match must carry the ETag from GET; if the member changed since, the response is 412 with the current member and ETag.
+// @Tags members
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int64 true "Member ID"
+// @Param If-Match header string true "ETag of the version being patched, or *"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} model.ResponseModel{result=model.ChurchMember} "Patched member"
+// @Header 200 {string} ETag "New member version"
+// @Failure 400 {object} model.ResponseModel "Invalid ID, malformed patch or invalid result"
+// @Failure 404 {object} model.ResponseModel "Member not found"
+// @Failure 409 {object} model.ResponseModel "Email already exists or a test operation failed"
+// @Failure 412 {object} model.ResponseModel{result=model.ChurchMember} "Member was modified; result holds the current version"
+// @Failure 415 {object} model.ResponseModel "Content-Type is not a patch format"
+// @Failure 428 {object} model.ResponseModel "If-Match header missing"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/{id} [patch]
+func (h *ChurchMemberHandler) PatchMemberHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Accept-Patch", acceptPatch)
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	p, err := decodePatch(r)
+	if err != nil {
+		respond.Error(w, r, err)
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	m, err := h.svc.PatchMember(r.Context(), id, version, p)
+	if err != nil {
+		h.writeError(w, r, id, err)
+		return
+	}
+	setETag(w, m.Version)
+	respond.JSON(w, http.StatusOK, m)
 }
 
 // DeleteMemberHandler handles DELETE /members/{id}
 // @Summary Delete a church member
-// @Description Delete church member by ID
+// @Description Move a church member to the trash. It can be restored with POST /members/{id}/restore until the retention period ends.
+// @Description If-Match must carry the ETag from GET; if the member changed since, the response is 412 with the current member and ETag.
 // @Tags members
 // @Param id path int64 true "Member ID"
+// @Param If-Match header string true "ETag of the version being deleted, or *"
 // @Success 204 {string} string "No content"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} model.ResponseModel "Invalid ID"
+// @Failure 404 {object} model.ResponseModel "Member not found"
+// @Failure 412 {object} model.ResponseModel{result=model.ChurchMember} "Member was modified; result holds the current version"
+// @Failure 428 {object} model.ResponseModel "If-Match header missing"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /members/{id} [delete]
 func (h *ChurchMemberHandler) DeleteMemberHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if err := h.svc.DeleteMember(r.Context(), id, version); err != nil {
+		h.writeError(w, r, id, err)
+		return
+	}
+	respond.NoContent(w)
+}
+
+// ListTrashHandler handles GET /members/trash?limit=20&cursor=...
+// @Summary List deleted church members
+// @Description Retrieve one page of deleted church members, most recently deleted first. They can be restored until the retention period ends and they are purged.
+// @Tags members
+// @Produce json
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of deleted members"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.ChurchMember]} "Page of deleted members"
+// @Failure 400 {object} model.ResponseModel "Invalid limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/trash [get]
+func (h *ChurchMemberHandler) ListTrashHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := pageRequest(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	list, err := h.svc.ListTrash(r.Context(), page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, list)
+}
+
+// RestoreMemberHandler handles POST /members/{id}/restore
+// @Summary Restore a deleted church member
+// @Description Take a church member out of the trash. Fails with 409 if another member has taken the email since. Restoring a merged duplicate removes its redirect to the survivor.
+// @Tags members
+// @Produce json
+// @Param id path int64 true "Member ID"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; a repeat replays the first response"
+// @Success 200 {object} model.ResponseModel{result=model.ChurchMember} "Restored member"
+// @Header 200 {string} Idempotent-Replayed "true when the response is a replay"
+// @Failure 400 {object} model.ResponseModel "Invalid ID"
+// @Failure 404 {object} model.ResponseModel "Member not in the trash"
+// @Failure 409 {object} model.ResponseModel "Email already exists, or Idempotency-Key still in use"
+// @Failure 422 {object} model.ResponseModel "Idempotency-Key already used for a different request"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/{id}/restore [post]
+func (h *ChurchMemberHandler) RestoreMemberHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
-	if err := h.svc.DeleteMember(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	m, err := h.svc.RestoreMember(r.Context(), id)
+	if err != nil {
+		respond.Error(w, r, err)
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	setETag(w, m.Version)
+	respond.JSON(w, http.StatusOK, m)
 }
 
-// ListMembersHandler handles GET /members
-// @Summary List all church members
-// @Description Retrieve all church members from the database, ordered by join date (newest first)
+// ListMembersHandler handles GET /members?filter[name][prefix]=jo&sort=-updated_at,name&limit=20&cursor=...
+// @Summary List church members
+// @Description Retrieve one page of church members, ordered by join date (newest first) unless sort is given. Pass next_cursor or prev_cursor from the previous page as cursor to move through the list.
+// @Description
+// @Description Filters use filter[field][operator]=value (filter[field]=value means eq) and are combined with AND.
+// @Description Fields and operators: id, joined_at, created_at, updated_at (eq, ne, gt, gte, lt, lte; dates as YYYY-MM-DD or RFC 3339);
+// @Description name, email (eq, ne, prefix, suffix, contains; case-insensitive); phone, address (same plus exists=true|false); biography (contains, exists).
+// @Description sort is a comma-separated list of id, name, email, joined_at, created_at, updated_at; prefix a field with - for descending.
 // @Tags members
 // @Produce json
-// @Success 200 {array} model.ChurchMember "List of members"
-// @Failure 500 {string} string "Internal server error"
+// @Param filter[field][operator] query string false "Filter, e.g. filter[email][suffix]=@example.com"
+// @Param sort query string false "Sort order, e.g. -updated_at,name"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of members"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.ChurchMember]} "Page of members"
+// @Failure 400 {object} model.ResponseModel "Unknown filter field or operator, invalid value, sort, limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /members [get]
 func (h *ChurchMemberHandler) ListMembersHandler(w http.ResponseWriter, r *http.Request) {
-	list, err := h.svc.ListMembers(r.Context())
+	q, err := listQuery(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respond.Error(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	if list == nil {
-		list = []*model.ChurchMember{}
+	page, err := pageRequest(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	list, err := h.svc.ListMembers(r.Context(), q, page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
 	}
-	json.NewEncoder(w).Encode(list)
+	respond.JSON(w, http.StatusOK, list)
 }
 
-// ListMembersByDateHandler handles GET /members/joined?start=2024-01-01&end=2024-12-31
+// ListMembersByDateHandler handles GET /members/joined?start=2024-01-01&end=2024-12-31&limit=20&cursor=...
 // @Summary List church members by joined date range
-// @Description Retrieve church members joined within a specific date range
+// @Description Retrieve one page of church members joined within a specific date range, newest first
 // @Tags members
 // @Produce json
 // @Param start query string true "Start date (YYYY-MM-DD)"
 // @Param end query string true "End date (YYYY-MM-DD)"
-// @Success 200 {array} model.ChurchMember "List of members"
-// @Failure 400 {string} string "Invalid date format"
-// @Failure 500 {string} string "Internal server error"
+// @Param filter[field][operator] query string false "Extra filter, same syntax as GET /members"
+// @Param sort query string false "Sort order, same syntax as GET /members"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of matching members"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.ChurchMember]} "Page of members"
+// @Failure 400 {object} model.ResponseModel "Invalid date, filter, sort, limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /members/joined [get]
 func (h *ChurchMemberHandler) ListMembersByDateHandler(w http.ResponseWriter, r *http.Request) {
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
-
-	if startStr == "" || endStr == "" {
-		http.Error(w, "start and end date parameters are required", http.StatusBadRequest)
+	var fields []apperror.FieldError
+	startDate, fe := queryDate(r, "start")
+	if fe != nil {
+		fields = append(fields, *fe)
+	}
+	endDate, fe := queryDate(r, "end")
+	if fe != nil {
+		fields = append(fields, *fe)
+	}
+	q, err := listQuery(r)
+	if err != nil {
+		fields = append(fields, apperror.As(err).Fields...)
+	}
+	page, err := pageRequest(r)
+	if err != nil {
+		fields = append(fields, apperror.As(err).Fields...)
+	}
+	if err := validation.Error(fields); err != nil {
+		respond.Error(w, r, err)
 		return
 	}
 
-	startDate, err := time.Parse("2006-01-02", startStr)
+	// Set end date to end of day
+	endDate = endDate.Add(24 * time.Hour)
+
+	list, err := h.svc.ListMembersByJoinedDate(r.Context(), startDate, endDate, q, page)
 	if err != nil {
-		http.Error(w, "invalid start date format (use YYYY-MM-DD)", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
+	respond.JSON(w, http.StatusOK, list)
+}
+
+// queryDate parses a required YYYY-MM-DD query parameter.
+func queryDate(r *http.Request, name string) (time.Time, *apperror.FieldError) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		fe := validation.Required(name)
+		return time.Time{}, &fe
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, &apperror.FieldError{Field: name, Code: "date", Message: name + " must be a date in YYYY-MM-DD format"}
+	}
+	return t, nil
+}
 
-	endDate, err := time.Parse("2006-01-02", endStr)
+// SearchMembersHandler handles GET /members/search?q=john&limit=20&cursor=...
+// @Summary Search church members
+// @Description Full-text search over name, email, address and biography. Every word is matched as a prefix and all words must match.
+// @Description Results are ordered by relevance; matching text is returned in highlight with terms wrapped in <mark> (not HTML-escaped).
+// @Tags members
+// @Produce json
+// @Param q query string true "Search text (max 200 characters)"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page of the same search"
+// @Param include_total query bool false "Also return the total number of matches"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.MemberSearchHit]} "Page of search hits"
+// @Failure 400 {object} model.ResponseModel "Missing q, invalid limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/search [get]
+func (h *ChurchMemberHandler) SearchMembersHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := pageRequest(r)
 	if err != nil {
-		http.Error(w, "invalid end date format (use YYYY-MM-DD)", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
+	hits, err := h.svc.SearchMembers(r.Context(), model.MemberSearch{Query: r.URL.Query().Get("q")}, page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, hits)
+}
 
-	// Set end date to end of day
-	endDate = endDate.Add(24 * time.Hour)
+// ListDuplicatesHandler handles GET /members/duplicates?min_score=0.6&limit=50
+// @Summary List likely duplicate members
+// @Description Score pairs of members by normalized name similarity, phone, address and email name, and return pairs at or above min_score, best first.
+// @Tags members
+// @Produce json
+// @Param min_score query number false "Minimum score between 0 and 1 (default 0.6)"
+// @Param limit query int false "Maximum pairs to return (1-500, default 50)"
+// @Success 200 {object} model.ResponseModel{result=[]model.DuplicateCandidate} "Duplicate candidates"
+// @Failure 400 {object} model.ResponseModel "Invalid min_score or limit"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/duplicates [get]
+func (h *ChurchMemberHandler) ListDuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	var q service.DuplicateQuery
+	var fields []apperror.FieldError
+	if v := r.URL.Query().Get("min_score"); v != "" {
+		score, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "min_score", Code: "number", Message: "min_score must be a number"})
+		}
+		q.MinScore = score
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "limit", Code: "number", Message: "limit must be a number"})
+		}
+		q.Limit = limit
+	}
+	if err := validation.Error(fields); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
 
-	list, err := h.svc.ListMembersByJoinedDate(r.Context(), startDate, endDate)
+	candidates, err := h.svc.FindDuplicates(r.Context(), q)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
+	respond.JSON(w, http.StatusOK, candidates)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if list == nil {
-		list = []*model.ChurchMember{}
+// MergeMemberHandler handles POST /members/{id}/merge
+// @Summary Merge a duplicate into a member
+// @Description Combine duplicate_id into the member in the path in one transaction. Fields keep the survivor's value unless it is empty or prefer names "duplicate" for them; joined_at keeps the earlier date.
+// @Description The duplicate moves to the trash and GET /members/{duplicate_id} redirects to the survivor afterwards.
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int64 true "Surviving member ID"
+// @Param merge body model.MergeRequest true "Duplicate to fold in and per-field preferences"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; a repeat replays the first response"
+// @Success 200 {object} model.ResponseModel{result=model.ChurchMember} "Merged member"
+// @Header 200 {string} Idempotent-Replayed "true when the response is a replay"
+// @Failure 400 {object} model.ResponseModel "Invalid request"
+// @Failure 404 {object} model.ResponseModel "Member or duplicate not found"
+// @Failure 409 {object} model.ResponseModel "Email already exists, or Idempotency-Key still in use"
+// @Failure 422 {object} model.ResponseModel "Idempotency-Key already used for a different request"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/{id}/merge [post]
+func (h *ChurchMemberHandler) MergeMemberHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	var in model.MergeRequest
+	if err := decodeJSON(r, &in); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	merged, err := h.svc.MergeMembers(r.Context(), id, in)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
 	}
-	json.NewEncoder(w).Encode(list)
+	setETag(w, merged.Version)
+	respond.JSON(w, http.StatusOK, merged)
 }
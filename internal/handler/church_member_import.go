@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/internal/service"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// maxImportSize caps an uploaded CSV file; larger imports go through `users import-members`.
+const maxImportSize = 10 << 20
+
+// columnParam matches columns[field].
+var columnParam = regexp.MustCompile(`^columns\[([a-z_]+)\]$`)
+
+// ImportMembersHandler handles POST /members/import?dry_run=true&columns[email]=E-mail
+// @Summary Import church members from CSV
+// @Description Upsert members from a CSV file, sent as the request body (Content-Type text/csv) or as the "file" field of a multipart form.
+// @Description The first row names the columns. By default a column feeds the member field of the same name (name, email, phone, address, biography, joined_at);
+// @Description columns[field]=Header maps a differently named column. A row whose email matches a live member updates that member's mapped fields; other rows create members.
+// @Description Every row is validated like POST /members; invalid rows are skipped and listed in the report. Rows are committed in batches, one transaction per batch.
+// @Description With dry_run=true nothing is saved but the report shows what would happen. With format=csv the report's errors are returned as a CSV download.
+// @Tags members
+// @Accept text/csv
+// @Accept mpfd
+// @Produce json
+// @Produce text/csv
+// @Param file formData file false "CSV file, when sent as multipart/form-data"
+// @Param columns[field] query string false "CSV header to read a member field from, e.g. columns[name]=Full Name"
+// @Param dry_run query bool false "Check the file without saving anything"
+// @Param batch_size query int false "Rows per transaction (1-1000, default 100)"
+// @Param format query string false "csv to download the errors as CSV instead of JSON"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; a repeat replays the first response"
+// @Success 200 {object} model.ResponseModel{result=model.ImportReport} "Import report"
+// @Header 200 {string} Idempotent-Replayed "true when the response is a replay"
+// @Failure 400 {object} model.ResponseModel "Invalid options, missing email column or file too large"
+// @Failure 409 {object} model.ResponseModel "Idempotency-Key still in use"
+// @Failure 422 {object} model.ResponseModel "Idempotency-Key already used for a different request"
+// @Failure 415 {object} model.ResponseModel "Body is neither CSV nor a multipart form"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /members/import [post]
+func (h *ChurchMemberHandler) ImportMembersHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := importOptions(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	file, err := importFile(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	report, err := h.svc.ImportMembers(r.Context(), file, opts)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		err = apperror.Wrap(err, apperror.KindValidation, "IMPORT_TOO_LARGE",
+			"CSV file is larger than "+strconv.Itoa(maxImportSize>>20)+" MB; split it or use the import-members command")
+	}
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="member-import-errors.csv"`)
+		if err := service.WriteImportReport(w, report); err != nil {
+			// The import itself is done; only the client's copy of the report is lost.
+			logger.FromContext(r.Context()).Error("writing member import report failed", "error", err)
+		}
+		return
+	}
+	respond.JSON(w, http.StatusOK, report)
+}
+
+// importOptions reads the dry_run, batch_size and columns[field] query parameters.
+func importOptions(r *http.Request) (model.ImportOptions, error) {
+	var opts model.ImportOptions
+	var fields []apperror.FieldError
+	q := r.URL.Query()
+	if v := q.Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "dry_run", Code: "bool", Message: "dry_run must be true or false"})
+		}
+		opts.DryRun = b
+	}
+	if v := q.Get("batch_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "batch_size", Code: "number", Message: "batch_size must be a number"})
+		}
+		opts.BatchSize = n
+	}
+	for key, values := range q {
+		if m := columnParam.FindStringSubmatch(key); m != nil {
+			if opts.Columns == nil {
+				opts.Columns = map[string]string{}
+			}
+			opts.Columns[m[1]] = values[0]
+		}
+	}
+	return opts, validation.Error(fields)
+}
+
+// importFile returns the uploaded CSV: the "file" part of a multipart form, or the body itself.
+func importFile(r *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "", "text/csv", "application/csv", "text/plain":
+		return r.Body, nil
+	case "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, apperror.Wrap(err, apperror.KindValidation, apperror.CodeInvalidBody, "invalid multipart form")
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil, validation.Error([]apperror.FieldError{validation.Required("file")})
+			}
+			if err != nil {
+				return nil, apperror.Wrap(err, apperror.KindValidation, apperror.CodeInvalidBody, "invalid multipart form")
+			}
+			if part.FormName() == "file" {
+				return part, nil
+			}
+		}
+	default:
+		return nil, apperror.UnsupportedMediaType(apperror.CodeUnsupportedType, "Content-Type must be text/csv or multipart/form-data")
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/example/golang-project/internal/apperror"
+)
+
+// errIfMatchRequired rejects writes that don't say which version they were based on.
+var errIfMatchRequired = apperror.PreconditionRequired(apperror.CodeIfMatchRequired,
+	"If-Match header is required; send the ETag returned when the record was read")
+
+// setETag sets the ETag header to the row version of the record in the response.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ifMatch reads the If-Match header required by PUT, PATCH and DELETE. It returns the
+// version the client last read, 0 for "*" (any current version), or -1 for a value that
+// can never match, such as a weak tag or a list of tags.
+func ifMatch(r *http.Request) (int64, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch {
+	case v == "":
+		return 0, errIfMatchRequired
+	case v == "*":
+		return 0, nil
+	case len(v) < 3 || v[0] != '"' || v[len(v)-1] != '"':
+		return -1, nil
+	}
+	version, err := strconv.ParseInt(v[1:len(v)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return -1, nil
+	}
+	return version, nil
+}
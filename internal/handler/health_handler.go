@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/pkg/db/migrate"
+)
+
+// healthCheckTimeout bounds each readiness check so a hung dependency can't hang the probe.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthHandler serves the liveness and readiness probes.
+type HealthHandler struct {
+	db           *sql.DB
+	migrator     *migrate.Migrator
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler creates a handler probing db and comparing its schema version
+// with the migrations embedded in migrator.
+func NewHealthHandler(db *sql.DB, migrator *migrate.Migrator) *HealthHandler {
+	return &HealthHandler{db: db, migrator: migrator}
+}
+
+// SetShuttingDown makes readiness fail from now on, so load balancers stop routing
+// new traffic while in-flight requests drain.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// LivenessHandler handles GET /healthz
+// @Summary Liveness probe
+// @Description Reports that the process is up; performs no dependency checks
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.ResponseModel{result=model.HealthReport} "Process alive"
+// @Router /healthz [get]
+func (h *HealthHandler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	respond.JSON(w, http.StatusOK, model.HealthReport{Status: model.HealthStatusUp})
+}
+
+// ReadinessHandler handles GET /readyz
+// @Summary Readiness probe
+// @Description Pings the database and checks the schema is at the embedded migration version. Fails once shutdown has begun.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.ResponseModel{result=model.HealthReport} "Ready"
+// @Failure 503 {object} model.ResponseModel{result=model.HealthReport} "Not ready"
+// @Router /readyz [get]
+func (h *HealthHandler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := model.HealthReport{Status: model.HealthStatusUp}
+
+	if h.shuttingDown.Load() {
+		report.Checks = append(report.Checks, model.HealthCheck{
+			Name:   "shutdown",
+			Status: model.HealthStatusDown,
+			Error:  "server is shutting down",
+		})
+	}
+	report.Checks = append(report.Checks,
+		runCheck(r.Context(), "database", h.checkDatabase),
+		runCheck(r.Context(), "migrations", h.checkMigrations),
+	)
+
+	for _, c := range report.Checks {
+		if c.Status != model.HealthStatusUp {
+			report.Status = model.HealthStatusDown
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != model.HealthStatusUp {
+		resp := model.NewErrorResponse("Service not ready")
+		resp.Result = report
+		respond.Envelope(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	respond.JSON(w, http.StatusOK, report)
+}
+
+func (h *HealthHandler) checkDatabase(ctx context.Context) error {
+	return h.db.PingContext(ctx)
+}
+
+func (h *HealthHandler) checkMigrations(ctx context.Context) error {
+	current, err := h.migrator.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if expected := h.migrator.LatestVersion(); current != expected {
+		return fmt.Errorf("schema version %d, expected %d", current, expected)
+	}
+	return nil
+}
+
+// runCheck executes fn with a timeout and records its status and latency.
+func runCheck(ctx context.Context, name string, fn func(context.Context) error) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	check := model.HealthCheck{
+		Name:      name,
+		Status:    model.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Status = model.HealthStatusDown
+		check.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			check.Error = "timed out after " + healthCheckTimeout.String()
+		}
+	}
+	return check
+}
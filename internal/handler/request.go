@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/jsonpatch"
+)
+
+// pathID parses the {id} route variable.
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return 0, apperror.Validation(apperror.CodeInvalidID, "invalid id")
+	}
+	return id, nil
+}
+
+// decodeJSON decodes the request body into v.
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return apperror.Wrap(err, apperror.KindValidation, apperror.CodeInvalidBody, "invalid request body")
+	}
+	return nil
+}
+
+// acceptPatch lists the patch formats PATCH endpoints accept, for the Accept-Patch header.
+const acceptPatch = jsonpatch.MergePatchType + ", " + jsonpatch.PatchType
+
+// decodePatch parses the request body as a merge patch or JSON Patch, chosen by Content-Type.
+func decodePatch(r *http.Request) (*jsonpatch.Patch, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.KindValidation, apperror.CodeInvalidBody, "invalid request body")
+	}
+	p, err := jsonpatch.Parse(r.Header.Get("Content-Type"), body)
+	if errors.Is(err, jsonpatch.ErrUnsupportedType) {
+		return nil, apperror.UnsupportedMediaType(apperror.CodeUnsupportedType, "Content-Type must be "+jsonpatch.MergePatchType+" or "+jsonpatch.PatchType)
+	}
+	if err != nil {
+		return nil, apperror.Validation(apperror.CodeInvalidPatch, "invalid patch: "+err.Error())
+	}
+	return p, nil
+}
+
+// pageRequest reads the limit, cursor and include_total query parameters.
+func pageRequest(r *http.Request) (model.PageRequest, error) {
+	q := r.URL.Query()
+	page := model.PageRequest{Cursor: q.Get("cursor")}
+	var fields []apperror.FieldError
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "limit", Code: "number", Message: "limit must be a number"})
+		}
+		page.Limit = n
+	}
+	if v := q.Get("include_total"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "include_total", Code: "bool", Message: "include_total must be true or false"})
+		}
+		page.IncludeTotal = b
+	}
+	return page, validation.Error(fields)
+}
+
+// filterParam matches filter[field][op]; filter[field] alone means eq.
+var filterParam = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z_]+)\])?$`)
+
+// listQuery reads filter[field][op]=value and sort=-field,field query parameters.
+// It only checks the syntax; the repository decides which fields and operators exist.
+func listQuery(r *http.Request) (model.ListQuery, error) {
+	var q model.ListQuery
+	var fields []apperror.FieldError
+	params := r.URL.Query()
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // deterministic SQL and error order
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "filter") {
+			continue
+		}
+		m := filterParam.FindStringSubmatch(key)
+		if m == nil {
+			fields = append(fields, apperror.FieldError{
+				Field: key, Code: "invalid_filter", Message: key + " must look like filter[field][operator]",
+			})
+			continue
+		}
+		op := m[2]
+		if op == "" {
+			op = "eq"
+		}
+		for _, v := range params[key] {
+			q.Filters = append(q.Filters, model.Filter{Field: m[1], Op: op, Value: v})
+		}
+	}
+
+	if v := params.Get("sort"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			sf := model.SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+			if sf.Field == "" {
+				fields = append(fields, apperror.FieldError{Field: "sort", Code: "invalid_sort", Message: "sort must be a comma-separated list of fields, each optionally prefixed with -"})
+				break
+			}
+			q.Sort = append(q.Sort, sf)
+		}
+	}
+	return q, validation.Error(fields)
+}
@@ -1,13 +1,11 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
-
-	"github.com/gorilla/mux"
 
 	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
 	"github.com/example/golang-project/internal/service"
 )
 
@@ -23,29 +21,36 @@ func NewUserHandler(svc *service.UserService) *UserHandler {
 
 // CreateUserHandler handles POST /users
 // @Summary Create a new user
-// @Description Create a new user with name and email
+// @Description Create a new user with name, email, password and optional role
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body model.User true "User data"
-// @Success 201 {object} map[string]int64 "User created"
-// @Failure 400 {string} string "Invalid request body"
-// @Failure 500 {string} string "Internal server error"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; a repeat replays the first response"
+// @Success 201 {object} model.ResponseModel{result=map[string]int64} "User created"
+// @Header 201 {string} ETag "Version of the new user"
+// @Header 201 {string} Idempotent-Replayed "true when the response is a replay"
+// @Failure 400 {object} model.ResponseModel "Invalid request body or validation error"
+// @Failure 409 {object} model.ResponseModel "Email already exists, or Idempotency-Key still in use"
+// @Failure 422 {object} model.ResponseModel "Idempotency-Key already used for a different request"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /users [post]
 func (h *UserHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 	var in model.User
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(r, &in); err != nil {
+		respond.Error(w, r, err)
 		return
 	}
 	id, err := h.svc.CreateUser(r.Context(), &in)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respond.Error(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]int64{"id": id})
+	setETag(w, in.Version)
+	respond.JSON(w, http.StatusCreated, map[string]int64{"id": id})
 }
 
 // GetUserHandler handles GET /users/{id}
@@ -54,102 +59,263 @@ func (h *UserHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request)
 // @Tags users
 // @Produce json
 // @Param id path int64 true "User ID"
-// @Success 200 {object} model.User "User data"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "User not found"
-// @Failure 500 {string} string "Internal server error"
+// @Success 200 {object} model.ResponseModel{result=model.User} "User data"
+// @Header 200 {string} ETag "User version; send it as If-Match on PUT, PATCH and DELETE"
+// @Failure 400 {object} model.ResponseModel "Invalid ID"
+// @Failure 404 {object} model.ResponseModel "User not found"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
 	u, err := h.svc.GetUser(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	if u == nil {
-		http.NotFound(w, r)
+		respond.Error(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(u)
+	setETag(w, u.Version)
+	respond.JSON(w, http.StatusOK, u)
 }
 
 // UpdateUserHandler handles PUT /users/{id}
 // @Summary Update a user
-// @Description Update user name and email by ID
+// @Description Update user name, email and optionally role or password by ID.
+// @Description If-Match must carry the ETag from GET; if the user changed since, the response is 412 with the current user and ETag.
 // @Tags users
 // @Accept json
 // @Param id path int64 true "User ID"
+// @Param If-Match header string true "ETag of the version being updated, or *"
 // @Param user body model.User true "Updated user data"
 // @Success 204 {string} string "No content"
-// @Failure 400 {string} string "Invalid request"
-// @Failure 500 {string} string "Internal server error"
+// @Header 204 {string} ETag "New user version"
+// @Failure 400 {object} model.ResponseModel "Invalid request"
+// @Failure 404 {object} model.ResponseModel "User not found"
+// @Failure 409 {object} model.ResponseModel "Email already exists"
+// @Failure 412 {object} model.ResponseModel{result=model.User} "User was modified; result holds the current version"
+// @Failure 428 {object} model.ResponseModel "If-Match header missing"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := pathID(r)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
 	var in model.User
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if err := decodeJSON(r, &in); err != nil {
+		respond.Error(w, r, err)
 		return
 	}
 	in.ID = id
+	if in.Version, err = ifMatch(r); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
 	if err := h.svc.UpdateUser(r.Context(), &in); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.writeError(w, r, id, err)
+		return
+	}
+	setETag(w, in.Version)
+	respond.NoContent(w)
+}
+
+// writeError answers a failed If-Match with 412 carrying the user's current state and
+// ETag, and any other error as usual.
+func (h *UserHandler) writeError(w http.ResponseWriter, r *http.Request, id int64, err error) {
+	if errors.Is(err, service.ErrUserModified) {
+		if current, gerr := h.svc.GetUser(r.Context(), id); gerr == nil {
+			setETag(w, current.Version)
+			respond.ErrorResult(w, r, err, current)
+			return
+		}
+	}
+	respond.Error(w, r, err)
+}
+
+// PatchUserHandler handles PATCH /users/{id}
+// @Summary Partially update a user
+// @Description Change only the fields in the body. With Content-Type application/merge-patch+json the body is a JSON Merge Patch (RFC 7396),
+// @Description e.g. {"phone":"555-0100","address":null} sets phone and clears address. With application/json-patch+json it is a JSON Patch (RFC 6902),
+// @Description e.g. [{"op":"test","path":"/email","value":"a@example.com"},{"op":"replace","path":"/name","value":"Ann"}].
+// @Description The patched user is validated like a PUT body and only changed columns are written. id, created_at, version and the like are read-only.
+// @Description If-Match must carry the ETag from GET; if the user changed since, the response is 412 with the current user and ETag.
+// @Tags users
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int64 true "User ID"
+// @Param If-Match header string true "ETag of the version being patched, or *"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} model.ResponseModel{result=model.User} "Patched user"
+// @Header 200 {string} ETag "New user version"
+// @Failure 400 {object} model.ResponseModel "Invalid ID, malformed patch or invalid result"
+// @Failure 404 {object} model.ResponseModel "User not found"
+// @Failure 409 {object} model.ResponseModel "Email already exists or a test operation failed"
+// @Failure 412 {object} model.ResponseModel{result=model.User} "User was modified; result holds the current version"
+// @Failure 415 {object} model.ResponseModel "Content-Type is not a patch format"
+// @Failure 428 {object} model.ResponseModel "If-Match header missing"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /users/{id} [patch]
+func (h *UserHandler) PatchUserHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Accept-Patch", acceptPatch)
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	version, err := ifMatch(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	p, err := decodePatch(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	u, err := h.svc.PatchUser(r.Context(), id, version, p)
+	if err != nil {
+		h.writeError(w, r, id, err)
+		return
+	}
+	setETag(w, u.Version)
+	respond.JSON(w, http.StatusOK, u)
 }
 
 // DeleteUserHandler handles DELETE /users/{id}
 // @Summary Delete a user
-// @Description Delete user by ID
+// @Description Move a user to the trash; a deleted user can no longer log in. It can be restored with POST /users/{id}/restore until the retention period ends.
+// @Description If-Match must carry the ETag from GET; if the user changed since, the response is 412 with the current user and ETag.
 // @Tags users
 // @Param id path int64 true "User ID"
+// @Param If-Match header string true "ETag of the version being deleted, or *"
 // @Success 204 {string} string "No content"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} model.ResponseModel "Invalid ID"
+// @Failure 404 {object} model.ResponseModel "User not found"
+// @Failure 412 {object} model.ResponseModel{result=model.User} "User was modified; result holds the current version"
+// @Failure 428 {object} model.ResponseModel "If-Match header missing"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		respond.Error(w, r, err)
 		return
 	}
-	if err := h.svc.DeleteUser(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.svc.DeleteUser(r.Context(), id, version); err != nil {
+		h.writeError(w, r, id, err)
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	respond.NoContent(w)
 }
 
-// ListUsersHandler handles GET /users
-// @Summary List all users
-// @Description Retrieve all users from the database
+// ListUsersHandler handles GET /users?limit=20&cursor=...
+// @Summary List users
+// @Description Retrieve one page of users ordered by ID. Pass next_cursor or prev_cursor from the previous page as cursor to move through the list.
 // @Tags users
 // @Produce json
-// @Success 200 {array} model.User "List of users"
-// @Failure 500 {string} string "Internal server error"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of users"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.User]} "Page of users"
+// @Failure 400 {object} model.ResponseModel "Invalid limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
 // @Router /users [get]
 func (h *UserHandler) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
-	list, err := h.svc.ListUsers(r.Context())
+	page, err := pageRequest(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	list, err := h.svc.ListUsers(r.Context(), page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, list)
+}
+
+// ListTrashHandler handles GET /users/trash?limit=20&cursor=...
+// @Summary List deleted users
+// @Description Retrieve one page of deleted users, most recently deleted first. They can be restored until the retention period ends and they are purged.
+// @Tags users
+// @Produce json
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Opaque cursor from a previous page"
+// @Param include_total query bool false "Also return the total number of deleted users"
+// @Success 200 {object} model.ResponseModel{result=model.Page[model.User]} "Page of deleted users"
+// @Failure 400 {object} model.ResponseModel "Invalid limit or cursor"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /users/trash [get]
+func (h *UserHandler) ListTrashHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := pageRequest(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	list, err := h.svc.ListTrash(r.Context(), page)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	respond.JSON(w, http.StatusOK, list)
+}
+
+// RestoreUserHandler handles POST /users/{id}/restore
+// @Summary Restore a deleted user
+// @Description Take a user out of the trash. Fails with 409 if another user has taken the email since.
+// @Tags users
+// @Produce json
+// @Param id path int64 true "User ID"
+// @Param Idempotency-Key header string false "Makes the request safe to retry; a repeat replays the first response"
+// @Success 200 {object} model.ResponseModel{result=model.User} "Restored user"
+// @Header 200 {string} Idempotent-Replayed "true when the response is a replay"
+// @Failure 400 {object} model.ResponseModel "Invalid ID"
+// @Failure 404 {object} model.ResponseModel "User not in the trash"
+// @Failure 409 {object} model.ResponseModel "Email already exists, or Idempotency-Key still in use"
+// @Failure 422 {object} model.ResponseModel "Idempotency-Key already used for a different request"
+// @Failure 500 {object} model.ResponseModel "Internal server error"
+// @Security BearerAuth
+// @Failure 401 {object} model.ResponseModel "Missing or invalid token"
+// @Failure 403 {object} model.ResponseModel "Role lacks the required permission"
+// @Router /users/{id}/restore [post]
+func (h *UserHandler) RestoreUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+	u, err := h.svc.RestoreUser(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respond.Error(w, r, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(list)
+	setETag(w, u.Version)
+	respond.JSON(w, http.StatusOK, u)
 }
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"bufio"
+	"database/sql"
+	"strconv"
+
+	"github.com/example/golang-project/pkg/db"
+)
+
+// HTTP metrics, recorded by middleware.MetricsMiddleware. The route label is the mux
+// path template (e.g. /members/{id}) so IDs don't explode the label cardinality.
+var (
+	HTTPRequests = Default.NewCounterVec("http_requests_total",
+		"Total HTTP requests by method, route template and status code.", "method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by method and route template.", DefaultLatencyBuckets, "method", "route")
+	HTTPInFlight = Default.NewGauge("http_requests_in_flight",
+		"HTTP requests currently being served.")
+)
+
+// Domain counters, incremented by the services after a successful write.
+var (
+	MembersCreated  = Default.NewCounter("church_members_created_total", "Church members created.")
+	MembersUpdated  = Default.NewCounter("church_members_updated_total", "Church members updated.")
+	MembersDeleted  = Default.NewCounter("church_members_deleted_total", "Church members moved to the trash.")
+	MembersMerged   = Default.NewCounter("church_members_merged_total", "Duplicate church members merged into another.")
+	MembersRestored = Default.NewCounter("church_members_restored_total", "Church members restored from the trash.")
+	MembersPurged   = Default.NewCounter("church_members_purged_total", "Trashed church members permanently deleted after the retention period.")
+	UsersCreated    = Default.NewCounter("users_created_total", "Users created.")
+	UsersUpdated    = Default.NewCounter("users_updated_total", "Users updated.")
+	UsersDeleted    = Default.NewCounter("users_deleted_total", "Users moved to the trash.")
+	UsersRestored   = Default.NewCounter("users_restored_total", "Users restored from the trash.")
+	UsersPurged     = Default.NewCounter("users_purged_total", "Trashed users permanently deleted after the retention period.")
+)
+
+// IdempotentReplays counts stored responses sent again for a repeated Idempotency-Key.
+var IdempotentReplays = Default.NewCounter("idempotency_replays_total", "Responses replayed for a repeated Idempotency-Key.")
+
+// dbStatsCollector exports sql.DBStats for a connection pool at scrape time.
+type dbStatsCollector struct {
+	db *sql.DB
+}
+
+// RegisterDBStats exposes the pool statistics of db (as opened by db.ConnectDB) on reg.
+func RegisterDBStats(reg *Registry, db *sql.DB) {
+	reg.Register(&dbStatsCollector{db: db},
+		"db_max_open_connections", "db_open_connections", "db_in_use_connections", "db_idle_connections",
+		"db_wait_count_total", "db_wait_duration_seconds_total",
+		"db_max_idle_closed_total", "db_max_idle_time_closed_total", "db_max_lifetime_closed_total",
+	)
+}
+
+// Collect implements Collector.
+func (c *dbStatsCollector) Collect(w *bufio.Writer) {
+	s := c.db.Stats()
+	gauge := func(name, help string, v float64) {
+		writeHeader(w, name, help, "gauge")
+		writeSample(w, name, nil, nil, v)
+	}
+	counter := func(name, help string, v float64) {
+		writeHeader(w, name, help, "counter")
+		writeSample(w, name, nil, nil, v)
+	}
+	gauge("db_max_open_connections", "Maximum number of open connections to the database.", float64(s.MaxOpenConnections))
+	gauge("db_open_connections", "Established connections, both in use and idle.", float64(s.OpenConnections))
+	gauge("db_in_use_connections", "Connections currently in use.", float64(s.InUse))
+	gauge("db_idle_connections", "Idle connections.", float64(s.Idle))
+	counter("db_wait_count_total", "Total connections waited for.", float64(s.WaitCount))
+	counter("db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", s.WaitDuration.Seconds())
+	counter("db_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.", float64(s.MaxIdleClosed))
+	counter("db_max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.", float64(s.MaxIdleTimeClosed))
+	counter("db_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.", float64(s.MaxLifetimeClosed))
+}
+
+// txRetryCollector exports db.Retries at scrape time.
+type txRetryCollector struct{}
+
+// RegisterTxRetries exposes how often transactions were retried after serialization
+// failures and deadlocks (see db.UnitOfWorkImpl.WithTransaction) on reg.
+func RegisterTxRetries(reg *Registry) {
+	reg.Register(txRetryCollector{}, "db_tx_retries_total", "db_tx_retries_exhausted_total")
+}
+
+// Collect implements Collector.
+func (txRetryCollector) Collect(w *bufio.Writer) {
+	r := db.Retries()
+	writeHeader(w, "db_tx_retries_total", "Transactions run again after a retryable error, by SQLSTATE.", "counter")
+	writeSample(w, "db_tx_retries_total", []string{"sqlstate"}, []string{"40001"}, float64(r.SerializationFailures))
+	writeSample(w, "db_tx_retries_total", []string{"sqlstate"}, []string{"40P01"}, float64(r.Deadlocks))
+	writeHeader(w, "db_tx_retries_exhausted_total", "Transactions that still failed with a retryable error after the last attempt.", "counter")
+	writeSample(w, "db_tx_retries_exhausted_total", nil, nil, float64(r.Exhausted))
+}
+
+// replicaCollector exports the health of the read replicas of a db.Router at scrape time.
+type replicaCollector struct {
+	router *db.Router
+}
+
+// RegisterReplicas exposes whether each read replica of router is in rotation on reg;
+// replicas are labelled by their position in the configuration, starting at 1.
+func RegisterReplicas(reg *Registry, router *db.Router) {
+	reg.Register(&replicaCollector{router: router}, "db_replica_up")
+}
+
+// Collect implements Collector.
+func (c *replicaCollector) Collect(w *bufio.Writer) {
+	writeHeader(w, "db_replica_up", "Whether the read replica passed its last health check (1) or not (0).", "gauge")
+	for i, healthy := range c.router.ReplicaHealth() {
+		v := 0.0
+		if healthy {
+			v = 1
+		}
+		writeSample(w, "db_replica_up", []string{"replica"}, []string{strconv.Itoa(i + 1)}, v)
+	}
+}
@@ -0,0 +1,371 @@
+// Package metrics is a small, dependency-free implementation of Prometheus-style counters,
+// gauges and histograms, exposed in the Prometheus text exposition format on /metrics.
+// Any Prometheus server (or plain curl) can scrape it; no client library is needed.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Collector writes one or more complete metric families (HELP, TYPE and samples).
+type Collector interface {
+	Collect(w *bufio.Writer)
+}
+
+// Registry holds collectors in registration order.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+	names      map[string]bool
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// Default is the process-wide registry served on /metrics.
+var Default = NewRegistry()
+
+// Register adds c to the registry. names are the metric families c writes; registering
+// the same name twice panics, since it would produce an invalid exposition.
+func (r *Registry) Register(c Collector, names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range names {
+		if r.names[n] {
+			panic("metrics: duplicate registration of " + n)
+		}
+		r.names[n] = true
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// Handler serves the registry in the Prometheus text format (version 0.0.4).
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.mu.Lock()
+		collectors := append([]Collector(nil), r.collectors...)
+		r.mu.Unlock()
+
+		bw := bufio.NewWriter(w)
+		for _, c := range collectors {
+			c.Collect(bw)
+		}
+		bw.Flush()
+	})
+}
+
+// NewCounter registers and returns a counter without labels.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.Register(collectorFunc(func(w *bufio.Writer) {
+		writeHeader(w, name, help, "counter")
+		writeSample(w, name, nil, nil, c.Value())
+	}), name)
+	return c
+}
+
+// NewCounterVec registers and returns a counter partitioned by the given labels.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{labels: labels, children: map[string]*labeledCounter{}}
+	r.Register(collectorFunc(func(w *bufio.Writer) {
+		writeHeader(w, name, help, "counter")
+		for _, child := range v.sorted() {
+			writeSample(w, name, v.labels, child.values, child.Value())
+		}
+	}), name)
+	return v
+}
+
+// NewGauge registers and returns a settable gauge.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	r.Register(collectorFunc(func(w *bufio.Writer) {
+		writeHeader(w, name, help, "gauge")
+		writeSample(w, name, nil, nil, g.Value())
+	}), name)
+	return g
+}
+
+// NewGaugeFunc registers a gauge whose value is computed by fn at scrape time.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.Register(collectorFunc(func(w *bufio.Writer) {
+		writeHeader(w, name, help, "gauge")
+		writeSample(w, name, nil, nil, fn())
+	}), name)
+}
+
+// NewCounterFunc registers a counter whose value is computed by fn at scrape time.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.Register(collectorFunc(func(w *bufio.Writer) {
+		writeHeader(w, name, help, "counter")
+		writeSample(w, name, nil, nil, fn())
+	}), name)
+}
+
+// NewHistogramVec registers and returns a histogram partitioned by the given labels.
+// buckets are upper bounds in increasing order; +Inf is added automatically.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	v := &HistogramVec{buckets: buckets, labels: labels, children: map[string]*labeledHistogram{}}
+	r.Register(collectorFunc(func(w *bufio.Writer) {
+		writeHeader(w, name, help, "histogram")
+		bucketLabels := append(append([]string(nil), v.labels...), "le")
+		for _, child := range v.sorted() {
+			counts, sum, count := child.snapshot()
+			var cumulative uint64
+			for i, upper := range v.buckets {
+				cumulative += counts[i]
+				writeSample(w, name+"_bucket", bucketLabels, append(append([]string(nil), child.values...), formatFloat(upper)), float64(cumulative))
+			}
+			writeSample(w, name+"_bucket", bucketLabels, append(append([]string(nil), child.values...), "+Inf"), float64(count))
+			writeSample(w, name+"_sum", v.labels, child.values, sum)
+			writeSample(w, name+"_count", v.labels, child.values, float64(count))
+		}
+	}), name)
+	return v
+}
+
+// DefaultLatencyBuckets suit HTTP and DB latencies in seconds.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collectorFunc func(w *bufio.Writer)
+
+func (f collectorFunc) Collect(w *bufio.Writer) { f(w) }
+
+// atomicFloat is a float64 updated with compare-and-swap.
+type atomicFloat struct {
+	bits uint64
+}
+
+func (f *atomicFloat) add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&f.bits)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&f.bits, old, next) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) set(v float64) { atomic.StoreUint64(&f.bits, math.Float64bits(v)) }
+
+func (f *atomicFloat) load() float64 { return math.Float64frombits(atomic.LoadUint64(&f.bits)) }
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	v atomicFloat
+}
+
+// Inc adds 1.
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add adds delta, which must not be negative.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.v.add(delta)
+}
+
+// Value returns the current count.
+func (c *Counter) Value() float64 { return c.v.load() }
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	v atomicFloat
+}
+
+// Set replaces the value.
+func (g *Gauge) Set(v float64) { g.v.set(v) }
+
+// Inc adds 1.
+func (g *Gauge) Inc() { g.v.add(1) }
+
+// Dec subtracts 1.
+func (g *Gauge) Dec() { g.v.add(-1) }
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 { return g.v.load() }
+
+// CounterVec is a set of counters sharing a name and label names.
+type CounterVec struct {
+	labels   []string
+	mu       sync.RWMutex
+	children map[string]*labeledCounter
+}
+
+type labeledCounter struct {
+	Counter
+	values []string
+}
+
+// WithLabelValues returns the counter for the given label values, creating it on first use.
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	key := labelKey(v.labels, values)
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return &child.Counter
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; !ok {
+		child = &labeledCounter{values: append([]string(nil), values...)}
+		v.children[key] = child
+	}
+	return &child.Counter
+}
+
+func (v *CounterVec) sorted() []*labeledCounter {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	out := make([]*labeledCounter, 0, len(v.children))
+	for _, c := range v.children {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return lessValues(out[i].values, out[j].values) })
+	return out
+}
+
+// Histogram counts observations into buckets.
+type Histogram struct {
+	upper  []float64
+	counts []uint64
+	count  uint64
+	sum    atomicFloat
+}
+
+// Observe records a single value.
+func (h *Histogram) Observe(v float64) {
+	for i, upper := range h.upper {
+		if v <= upper {
+			atomic.AddUint64(&h.counts[i], 1)
+			break
+		}
+	}
+	h.sum.add(v)
+	atomic.AddUint64(&h.count, 1)
+}
+
+// snapshot returns per-bucket (non-cumulative) counts, the sum and the total count.
+func (h *Histogram) snapshot() ([]uint64, float64, uint64) {
+	counts := make([]uint64, len(h.counts))
+	for i := range h.counts {
+		counts[i] = atomic.LoadUint64(&h.counts[i])
+	}
+	return counts, h.sum.load(), atomic.LoadUint64(&h.count)
+}
+
+// HistogramVec is a set of histograms sharing a name, buckets and label names.
+type HistogramVec struct {
+	buckets  []float64
+	labels   []string
+	mu       sync.RWMutex
+	children map[string]*labeledHistogram
+}
+
+type labeledHistogram struct {
+	Histogram
+	values []string
+}
+
+// WithLabelValues returns the histogram for the given label values, creating it on first use.
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	key := labelKey(v.labels, values)
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return &child.Histogram
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; !ok {
+		child = &labeledHistogram{
+			Histogram: Histogram{upper: v.buckets, counts: make([]uint64, len(v.buckets))},
+			values:    append([]string(nil), values...),
+		}
+		v.children[key] = child
+	}
+	return &child.Histogram
+}
+
+func (v *HistogramVec) sorted() []*labeledHistogram {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	out := make([]*labeledHistogram, 0, len(v.children))
+	for _, h := range v.children {
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return lessValues(out[i].values, out[j].values) })
+	return out
+}
+
+func labelKey(labels, values []string) string {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("metrics: expected %d label values, got %d", len(labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func lessValues(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.ReplaceAll(help, "\n", " "), name, typ)
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(l)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabel(values[i]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// AuthMiddleware requires a valid "Authorization: Bearer <token>" header on every request
+// except those matching publicRoutes, and stores the caller's identity in the context.
+//
+// A public route ending in "/" matches that path prefix (e.g. "/swagger/"); any other
+// entry must match the request path exactly (e.g. "/healthz").
+func AuthMiddleware(tokens *auth.TokenManager, publicRoutes []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicRoute(r.URL.Path, publicRoutes) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				respond.Error(w, r, apperror.Unauthorized(apperror.CodeUnauthenticated, "authentication required"))
+				return
+			}
+			id, err := tokens.Parse(token)
+			if err != nil {
+				respond.Error(w, r, apperror.Unauthorized(apperror.CodeInvalidToken, err.Error()))
+				return
+			}
+
+			ctx := auth.WithIdentity(r.Context(), id)
+			ctx = logger.With(ctx, "user_id", id.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func isPublicRoute(path string, publicRoutes []string) bool {
+	for _, p := range publicRoutes {
+		if strings.HasSuffix(p, "/") {
+			if strings.HasPrefix(path, p) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(h, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/respond"
+)
+
+// RequirePermission only lets the request through if the authenticated caller's role
+// grants perm; it must run after AuthMiddleware. Otherwise it responds 401 (no caller)
+// or 403 (caller lacks the permission) in the ResponseModel envelope.
+func RequirePermission(perm auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := auth.FromContext(r.Context())
+			if id == nil {
+				respond.Error(w, r, apperror.Unauthorized(apperror.CodeUnauthenticated, "authentication required"))
+				return
+			}
+			if !id.Can(perm) {
+				respond.Error(w, r, apperror.Forbidden(apperror.CodeForbidden, "forbidden: missing permission "+string(perm)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
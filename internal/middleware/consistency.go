@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/example/golang-project/pkg/db"
+)
+
+// ReadYourWritesMiddleware gives every request its own db session, so once the request
+// has written, its remaining reads go to the primary instead of a replica that may not
+// have the change yet (see db.WithSession).
+func ReadYourWritesMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(db.WithSession(r.Context())))
+	})
+}
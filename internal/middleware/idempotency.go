@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/metrics"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// Idempotency-Key limits. A request body is buffered to fingerprint it, so the cap matches
+// the largest body an endpoint accepts (the CSV import); responses larger than
+// maxIdempotentResponse are not stored and the key is released instead.
+const (
+	maxIdempotencyKey     = 255
+	maxIdempotentBody     = 10 << 20
+	maxIdempotentResponse = 1 << 20
+
+	// idempotencyLockTimeout is how long a key stays claimed by a request that never
+	// completed (e.g. the instance serving it crashed) before another request may take it.
+	idempotencyLockTimeout = 5 * time.Minute
+)
+
+// Idempotency error codes.
+const (
+	CodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyInUse   = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// replayedHeaders are the response headers stored with a key and sent again on replay.
+// Request-scoped headers such as X-Request-ID are left out.
+var replayedHeaders = []string{"Content-Type", "Content-Disposition", "Location", "ETag", "Accept-Patch"}
+
+// IdempotencyStore persists Idempotency-Keys; see repository.IdempotencyRepository.
+type IdempotencyStore interface {
+	Claim(ctx context.Context, rec *model.IdempotencyRecord, staleBefore time.Time) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, rec *model.IdempotencyRecord) error
+	Release(ctx context.Context, rec *model.IdempotencyRecord) error
+}
+
+// IdempotencyMiddleware makes POST requests that carry an Idempotency-Key header safe to
+// retry. The first request with a key runs normally and its response is stored for ttl;
+// a repeat from the same user with the same method, URL and body gets that response
+// replayed with "Idempotent-Replayed: true" and the handler does not run again.
+//
+// A repeat with a different request is rejected with 422, and one that arrives while the
+// first is still running with 409. Server errors (5xx), 401 and 403 responses are not
+// stored, so the request can be retried with the same key. Keys are scoped to the
+// authenticated user; requests without an identity (e.g. login) pass straight through.
+// Register it with router.Use after AuthMiddleware.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, sent := r.Header["Idempotency-Key"]
+			id := auth.FromContext(r.Context())
+			if r.Method != http.MethodPost || !sent || id == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) != 1 || !validIdempotencyKey(key[0]) {
+				respond.Error(w, r, apperror.Validation(CodeInvalidIdempotencyKey,
+					"Idempotency-Key must be 1 to "+strconv.Itoa(maxIdempotencyKey)+" printable ASCII characters"))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					respond.Error(w, r, apperror.Validation(apperror.CodeInvalidBody,
+						"request body is larger than "+strconv.Itoa(maxIdempotentBody>>20)+" MB"))
+					return
+				}
+				respond.Error(w, r, apperror.Wrap(err, apperror.KindValidation, apperror.CodeInvalidBody, "could not read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now().UTC().Truncate(time.Microsecond) // Postgres timestamp precision
+			rec := &model.IdempotencyRecord{
+				UserID:      id.UserID,
+				Key:         key[0],
+				Fingerprint: fingerprint(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			}
+			existing, err := store.Claim(r.Context(), rec, now.Add(-idempotencyLockTimeout))
+			if err != nil {
+				respond.Error(w, r, apperror.Internal(err))
+				return
+			}
+			if existing != nil {
+				replay(w, r, existing, rec.Fingerprint)
+				return
+			}
+
+			// The outcome is saved even if the client has gone away: that is exactly when
+			// it will retry.
+			ctx := context.WithoutCancel(r.Context())
+			log := logger.FromContext(r.Context())
+			rw := &recordingWriter{ResponseWriter: w}
+			defer func() {
+				if p := recover(); p != nil {
+					if err := store.Release(ctx, rec); err != nil {
+						log.Error("releasing idempotency key failed", "error", err)
+					}
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+
+			if !rw.storable() {
+				if err := store.Release(ctx, rec); err != nil {
+					log.Error("releasing idempotency key failed", "error", err)
+				}
+				return
+			}
+			rec.Status = rw.status()
+			rec.Header = http.Header{}
+			for _, h := range replayedHeaders {
+				if v := w.Header().Values(h); len(v) > 0 {
+					rec.Header[h] = v
+				}
+			}
+			rec.Body = rw.body.Bytes()
+			if err := store.Complete(ctx, rec); err != nil {
+				log.Error("storing idempotent response failed", "error", err)
+			}
+		})
+	}
+}
+
+// replay answers a request whose key is already held by existing.
+func replay(w http.ResponseWriter, r *http.Request, existing *model.IdempotencyRecord, fp string) {
+	switch {
+	case existing.Fingerprint != fp:
+		respond.Error(w, r, apperror.Unprocessable(CodeIdempotencyKeyReused,
+			"Idempotency-Key was already used for a different request"))
+	case existing.Status == 0:
+		w.Header().Set("Retry-After", "1")
+		respond.Error(w, r, apperror.Conflict(CodeIdempotencyKeyInUse,
+			"a request with this Idempotency-Key is still being processed"))
+	default:
+		for h, v := range existing.Header {
+			w.Header()[h] = v
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(existing.Status)
+		w.Write(existing.Body)
+		metrics.IdempotentReplays.Inc()
+		logger.FromContext(r.Context()).Info("idempotent response replayed", "status", existing.Status)
+	}
+}
+
+// fingerprint identifies a request by method, URL and body.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+"\n"+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKey {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// recordingWriter passes a response through and keeps a copy of it for storage.
+type recordingWriter struct {
+	http.ResponseWriter
+	code     int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if rw.code == 0 {
+		rw.code = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.code == 0 {
+		rw.code = http.StatusOK
+	}
+	if !rw.overflow {
+		if rw.body.Len()+len(b) > maxIdempotentResponse {
+			rw.overflow = true
+			rw.body = bytes.Buffer{}
+		} else {
+			rw.body.Write(b)
+		}
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingWriter) status() int {
+	if rw.code == 0 {
+		return http.StatusOK
+	}
+	return rw.code
+}
+
+// storable reports whether the response should be replayed for repeats: anything but a
+// server error or an authentication failure, as long as it fits.
+func (rw *recordingWriter) storable() bool {
+	s := rw.status()
+	return !rw.overflow && s < http.StatusInternalServerError && s != http.StatusUnauthorized && s != http.StatusForbidden
+}
+
+// Flush lets streaming handlers flush through the wrapper.
+func (rw *recordingWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.code == 0 {
+			rw.code = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
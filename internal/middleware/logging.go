@@ -1,30 +1,47 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/respond"
+	"github.com/example/golang-project/pkg/logger"
 )
 
-// LoggingMiddleware logs incoming HTTP requests and response times.
+// LoggingMiddleware attaches method/path to the request logger and logs one structured
+// line per request once the handler has finished, including status, size and duration.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
-		log.Printf("[%s] %s %s %s", r.Method, r.URL.Path, r.RemoteAddr, time.Since(startTime))
-		next.ServeHTTP(w, r)
-		elapsedTime := time.Since(startTime)
-		log.Printf("[%s] %s completed in %v", r.Method, r.URL.Path, elapsedTime)
+		ctx := logger.With(r.Context(), "method", r.Method, "path", r.URL.Path)
+		rec := NewStatusRecorder(w)
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		logger.FromContext(ctx).Info("request completed",
+			"status", rec.Status(),
+			"bytes", rec.BytesWritten(),
+			"duration_ms", float64(time.Since(startTime).Microseconds())/1000,
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		)
 	})
 }
 
-// RecoveryMiddleware recovers from panics and returns an error response.
+// RecoveryMiddleware recovers from panics and returns an error response. It leaves
+// http.ErrAbortHandler alone, which handlers use to cut off a response already under way.
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
-				log.Printf("[PANIC] %s %s: %v", r.Method, r.URL.Path, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(`{"success":false,"code":"01","message":"Internal server error"}`))
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				logger.FromContext(r.Context()).Error("panic recovered", "panic", p, "stack", string(debug.Stack()))
+				respond.Error(w, r, apperror.Internal(fmt.Errorf("panic: %v", p)))
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/example/golang-project/internal/metrics"
+)
+
+// MetricsMiddleware records request count and latency per route template.
+// Register it with router.Use so mux has already matched the route. mux runs Use
+// middleware only for matched routes, so also call CountUnmatched on the router;
+// requests that match no route are recorded under route="unmatched".
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if tpl, err := cr.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		start := time.Now()
+		rec := NewStatusRecorder(w)
+		defer func() {
+			status := rec.Status()
+			p := recover()
+			if p != nil {
+				status = http.StatusInternalServerError
+			}
+			metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+			if p != nil {
+				panic(p) // let RecoveryMiddleware respond
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// CountUnmatched makes r answer requests that match no route (404) or no method of a
+// route (405) through MetricsMiddleware, which mux's own fallbacks bypass. Responses
+// are unchanged: an empty 405 and the standard 404 page.
+func CountUnmatched(r *mux.Router) {
+	r.NotFoundHandler = MetricsMiddleware(http.NotFoundHandler())
+	r.MethodNotAllowedHandler = MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/example/golang-project/internal/metrics"
+)
+
+func TestMetricsMiddlewareCountsUnmatched(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(MetricsMiddleware)
+	CountUnmatched(r)
+	r.HandleFunc("/members/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	tests := []struct {
+		method, path, route string
+		status              int
+	}{
+		{"GET", "/members/1", "/members/{id}", http.StatusOK},
+		{"GET", "/no/such/path", "unmatched", http.StatusNotFound},
+		{"DELETE", "/members/1", "unmatched", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			counter := metrics.HTTPRequests.WithLabelValues(tt.method, tt.route, strconv.Itoa(tt.status))
+			before := counter.Value()
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := counter.Value() - before; got != 1 {
+				t.Errorf("http_requests_total{method=%q,route=%q,status=\"%d\"} grew by %v, want 1", tt.method, tt.route, tt.status, got)
+			}
+		})
+	}
+}
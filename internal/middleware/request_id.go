@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// RequestIDHeader is read from incoming requests and echoed on every response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength caps client-supplied IDs so they can't bloat logs.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware honours an incoming X-Request-ID (or generates one), sets it on the
+// response and stores it, together with a request-scoped logger, in the request context.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := logger.WithRequestID(r.Context(), id)
+		ctx = logger.With(ctx, "request_id", id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts non-empty, printable ASCII IDs of reasonable length.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
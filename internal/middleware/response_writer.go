@@ -0,0 +1,67 @@
+package middleware
+
+import "net/http"
+
+// StatusRecorder wraps an http.ResponseWriter to capture the status code and the
+// number of body bytes written, for logging and metrics.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// NewStatusRecorder wraps w. If w is already a *StatusRecorder it is returned as is.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	if rec, ok := w.(*StatusRecorder); ok {
+		return rec
+	}
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+// WriteHeader records the status code before delegating.
+func (rec *StatusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body size; an implicit 200 is recorded if WriteHeader wasn't called.
+func (rec *StatusRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Status returns the response status (200 if the handler wrote nothing).
+func (rec *StatusRecorder) Status() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
+
+// BytesWritten returns the number of body bytes written so far.
+func (rec *StatusRecorder) BytesWritten() int64 {
+	return rec.bytes
+}
+
+// Flush lets streaming handlers flush through the wrapper.
+func (rec *StatusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		if !rec.wroteHeader {
+			rec.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (rec *StatusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
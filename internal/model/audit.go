@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// AuditEntry is one row of the audit trail: who changed which record, how, and what
+// the changed fields held before and after.
+type AuditEntry struct {
+	ID         int64                  `json:"id"`
+	Entity     string                 `json:"entity"` // member or user
+	EntityID   int64                  `json:"entity_id"`
+	Action     string                 `json:"action"`             // create, update, delete, restore, merge or purge
+	ActorID    *int64                 `json:"actor_id,omitempty"` // unset for background jobs
+	ActorEmail string                 `json:"actor_email,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// FieldChange is the value of one field before and after a change; Old is null on
+// create and New is null when a field was cleared.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
@@ -4,13 +4,59 @@ import "time"
 
 // ChurchMember represents a church member with their biography and contact information.
 type ChurchMember struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone,omitempty"`
-	Address   string    `json:"address,omitempty"`
-	Biography string    `json:"biography,omitempty"`
-	JoinedAt  time.Time `json:"joined_at"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64      `json:"id" db:"id,pk"`
+	Name      string     `json:"name" db:"name" validate:"required,min=2,max=255"`
+	Email     string     `json:"email" db:"email" validate:"required,email,max=255"`
+	Phone     string     `json:"phone,omitempty" db:"phone" validate:"phone,max=20"`
+	Address   string     `json:"address,omitempty" db:"address" validate:"max=500"`
+	Biography string     `json:"biography,omitempty" db:"biography" validate:"max=5000"`
+	JoinedAt  time.Time  `json:"joined_at" db:"joined_at" validate:"after=1900-01-01,before=now"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // set only on members listed from the trash
+	Version   int64      `json:"version" db:"version,version"`         // incremented by every write; served as the ETag
+}
+
+// DateRange is an inclusive time window used to filter list queries.
+type DateRange struct {
+	Start time.Time `json:"start" validate:"required"`
+	End   time.Time `json:"end" validate:"required,gtefield=Start"`
+}
+
+// MemberSearchHit is one full-text search result: the member, its relevance and the
+// matching text with search terms wrapped in <mark>...</mark>. Highlights are not
+// HTML-escaped; escape the member text before rendering it as HTML.
+type MemberSearchHit struct {
+	ChurchMember
+	Rank      float64         `json:"rank"`
+	Highlight MemberHighlight `json:"highlight"`
+}
+
+// MemberHighlight holds highlighted fragments; Address and Biography are only set when they matched.
+type MemberHighlight struct {
+	Name      string `json:"name"`
+	Address   string `json:"address,omitempty"`
+	Biography string `json:"biography,omitempty"`
+}
+
+// MemberSearch is the input of a member search.
+type MemberSearch struct {
+	Query string `json:"q" validate:"required,max=200"`
+}
+
+// DuplicateCandidate is a pair of members that probably describe the same person.
+// Score is between 0 and 1; Reasons lists what matched, e.g. "same phone".
+type DuplicateCandidate struct {
+	Member    ChurchMember `json:"member"`
+	Duplicate ChurchMember `json:"duplicate"`
+	Score     float64      `json:"score"`
+	Reasons   []string     `json:"reasons"`
+}
+
+// MergeRequest folds DuplicateID into the member named in the URL, which survives.
+// Prefer picks the source of individual fields ("survivor" or "duplicate"); fields not
+// listed keep the survivor's value unless it is empty. joined_at always keeps the earlier date.
+type MergeRequest struct {
+	DuplicateID int64             `json:"duplicate_id" validate:"required"`
+	Prefer      map[string]string `json:"prefer,omitempty"`
 }
@@ -0,0 +1,8 @@
+package model
+
+// ExportOptions controls a member export. Columns are member fields by JSON name, in
+// output order; empty means the default set.
+type ExportOptions struct {
+	Format  string   `json:"format" validate:"required,oneof=csv xlsx vcf"`
+	Columns []string `json:"columns,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is a stored Idempotency-Key: the request that first used it and,
+// once that request has finished, the response to replay for repeats.
+type IdempotencyRecord struct {
+	UserID      int64
+	Key         string
+	Fingerprint string      // sha256 of method, path and body
+	Status      int         // 0 while the first request is still running
+	Header      http.Header // replayed response headers
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
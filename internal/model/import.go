@@ -0,0 +1,34 @@
+package model
+
+import "github.com/example/golang-project/internal/apperror"
+
+// ImportOptions controls a CSV import of church members.
+type ImportOptions struct {
+	// Columns maps member fields (name, email, phone, address, biography, joined_at) to
+	// CSV header names. Fields not listed are read from the column with the field's own
+	// name, if there is one.
+	Columns   map[string]string `json:"columns,omitempty"`
+	DryRun    bool              `json:"dry_run"`
+	BatchSize int               `json:"batch_size" validate:"min=1,max=1000"` // rows per transaction; 0 means 100
+}
+
+// ImportReport summarizes a CSV import. Rows counts the data rows read; each ends up
+// created, updated (an existing member with the same email), unchanged or failed.
+// In a dry run the counts say what would have happened and nothing is saved.
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Rows      int              `json:"rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ImportRowError explains why one CSV row was not imported. Line is where the row
+// starts in the file, counting the header as line 1.
+type ImportRowError struct {
+	Line   int                   `json:"line"`
+	Email  string                `json:"email,omitempty"`
+	Errors []apperror.FieldError `json:"errors"`
+}
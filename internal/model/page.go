@@ -0,0 +1,25 @@
+package model
+
+// Default and maximum page sizes for list endpoints.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageRequest selects one page of a keyset-paginated list.
+// Cursor is opaque to clients: pass back next_cursor or prev_cursor from a previous Page.
+type PageRequest struct {
+	Limit        int    `json:"limit" validate:"min=1,max=100"` // 0 means DefaultPageLimit
+	Cursor       string `json:"cursor"`
+	IncludeTotal bool   `json:"include_total"` // also count every matching row (costs an extra query)
+}
+
+// Page is one page of results plus the cursors to move forwards and backwards.
+// An empty NextCursor or PrevCursor means there is nothing further in that direction.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
@@ -0,0 +1,22 @@
+package model
+
+// Filter is one condition of a list query, e.g. {Field: "joined_at", Op: "gte", Value: "2024-01-01"}.
+// Which fields and operators are allowed is decided by the repository serving the list.
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// SortField orders a list query by one field.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery holds the filters (combined with AND) and sort order requested for a list.
+// An empty Sort means the list's default order.
+type ListQuery struct {
+	Filters []Filter
+	Sort    []SortField
+}
@@ -1,12 +1,17 @@
 package model
 
+import "github.com/example/golang-project/internal/apperror"
+
 // ResponseModel is a standardized API response structure (similar to .NET's ResponseModel).
 // All API responses follow this format for consistency.
 type ResponseModel struct {
-	Success bool        `json:"success"`
-	Code    string      `json:"code"`    // "00" = success, "01" = error
-	Message string      `json:"message"`
-	Result  interface{} `json:"result,omitempty"`
+	Success   bool        `json:"success"`
+	Code      string      `json:"code"`                 // "00" = success, "01" = error
+	ErrorCode string      `json:"error_code,omitempty"` // stable machine-readable code, e.g. "MEMBER_NOT_FOUND"
+	Message   string      `json:"message"`
+	Result    interface{} `json:"result,omitempty"`
+	// Errors lists every invalid field when ErrorCode is VALIDATION_FAILED.
+	Errors []apperror.FieldError `json:"errors,omitempty"`
 }
 
 // NewSuccessResponse creates a successful response.
@@ -4,8 +4,14 @@ import "time"
 
 // User represents the users table in the database.
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name" validate:"required,min=1,max=255"`
-	Email     string    `json:"email" validate:"required,email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64      `json:"id" db:"id,pk"`
+	Name      string     `json:"name" db:"name" validate:"required,min=1,max=255"`
+	Email     string     `json:"email" db:"email" validate:"required,email,max=255"`
+	Role      string     `json:"role,omitempty" db:"role" validate:"oneof=admin staff member viewer"` // see auth.Role; empty means member on create, unchanged on update
+	Password  string     `json:"password,omitempty" db:"-" validate:"min=8,maxbytes=72"`              // write-only: accepted on create/update, never returned; at most 72 bytes (bcrypt)
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // set only on users listed from the trash
+	Version   int64      `json:"version" db:"version,version"`         // incremented by every write; served as the ETag
+
+	PasswordHash string `json:"-" db:"password_hash"`
 }
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/pkg/db"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// Audited entities.
+const (
+	auditMember = "member"
+	auditUser   = "user"
+)
+
+// Audit actions.
+const (
+	auditCreate  = "create"
+	auditUpdate  = "update"
+	auditDelete  = "delete"
+	auditRestore = "restore"
+	auditMerge   = "merge"
+	auditPurge   = "purge"
+)
+
+// redacted replaces secret values in the audit trail.
+const redacted = "[redacted]"
+
+// auditIgnored are JSON fields left out of diffs: identifiers and bookkeeping fields
+// that change on every write, and the plaintext password accepted on user writes.
+var auditIgnored = map[string]bool{"id": true, "created_at": true, "updated_at": true, "version": true, "password": true}
+
+// AuditRepository reads the audit trail. Entries are written by the other repositories,
+// inside the transaction of the change they record; nothing ever updates or deletes them.
+type AuditRepository struct {
+	base *BaseRepository
+}
+
+// NewAuditRepository creates a new audit repository on the pools of router.
+func NewAuditRepository(router *db.Router) *AuditRepository {
+	return &AuditRepository{base: NewBaseRepository(router)}
+}
+
+const auditColumns = `id, entity, entity_id, action, actor_id, COALESCE(actor_email, ''), COALESCE(request_id, ''), changes, created_at`
+
+// auditKeys is the default order: newest first.
+var auditKeys = []keyColumn{{name: "id", kind: keyInt, desc: true}}
+
+// auditFields whitelists what List may filter and sort on.
+var auditFields = map[string]fieldSpec{
+	"id":          {column: "id", kind: keyInt, ops: rangeOps, sortable: true},
+	"entity":      {column: "entity", kind: keyString, ops: []string{opEq, opNe}},
+	"entity_id":   {column: "entity_id", kind: keyInt, ops: rangeOps},
+	"action":      {column: "action", kind: keyString, ops: []string{opEq, opNe}},
+	"actor_id":    {column: "actor_id", kind: keyInt, ops: rangeOps},
+	"actor_email": {column: "actor_email", kind: keyString, ops: textOps},
+	"request_id":  {column: "request_id", kind: keyString, ops: []string{opEq}},
+	"created_at":  {column: "created_at", kind: keyTime, ops: rangeOps, sortable: true},
+}
+
+func scanAudit(rows *sql.Rows) (*model.AuditEntry, error) {
+	var e model.AuditEntry
+	var changes []byte
+	var actorID sql.NullInt64
+	if err := rows.Scan(&e.ID, &e.Entity, &e.EntityID, &e.Action, &actorID, &e.ActorEmail, &e.RequestID, &changes, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	if actorID.Valid {
+		e.ActorID = &actorID.Int64
+	}
+	e.Changes = map[string]model.FieldChange{}
+	if err := json.Unmarshal(changes, &e.Changes); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns one page of audit entries matching q, newest first unless q.Sort says otherwise.
+func (r *AuditRepository) List(ctx context.Context, q model.ListQuery, page model.PageRequest) (*model.Page[*model.AuditEntry], error) {
+	where, args, err := buildFilters(auditFields, q.Filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := buildSort(auditFields, q.Sort, auditKeys)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(ctx, r.base, keyset{
+		columns: auditColumns,
+		from:    "audit_log",
+		where:   where,
+		args:    args,
+		keys:    keys,
+	}, page, scanAudit, func(e *model.AuditEntry) []interface{} {
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			if k.name == "created_at" {
+				values[i] = e.CreatedAt
+			} else {
+				values[i] = e.ID
+			}
+		}
+		return values
+	})
+}
+
+// MemberHistory returns one page of the audit entries of a church member, newest first.
+// Deleted and purged members keep their history.
+func (r *AuditRepository) MemberHistory(ctx context.Context, memberID int64, page model.PageRequest) (*model.Page[*model.AuditEntry], error) {
+	return paginate(ctx, r.base, keyset{
+		columns: auditColumns,
+		from:    "audit_log",
+		where:   []string{"entity = $1", "entity_id = $2"},
+		args:    []interface{}{auditMember, memberID},
+		keys:    auditKeys,
+	}, page, scanAudit, func(e *model.AuditEntry) []interface{} {
+		return []interface{}{e.ID}
+	})
+}
+
+// recordAudit appends an audit entry through base; ctx should carry the transaction
+// making the change. The actor and request ID come from ctx.
+// Updates that changed nothing are not recorded.
+func recordAudit(ctx context.Context, base *BaseRepository, entity string, id int64, action string, changes map[string]model.FieldChange) error {
+	if action == auditUpdate && len(changes) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	var actorID interface{}
+	var actorEmail string
+	if who := auth.FromContext(ctx); who != nil {
+		actorID, actorEmail = who.UserID, who.Email
+	}
+	return base.ExecUpdate(ctx,
+		`INSERT INTO audit_log (entity, entity_id, action, actor_id, actor_email, request_id, changes, created_at)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)`,
+		entity, id, action, actorID, actorEmail, logger.RequestID(ctx), string(b), time.Now().UTC(),
+	)
+}
+
+// purgeAudited permanently deletes up to limit rows of table trashed before cutoff and
+// records a purge entry for each, in a single statement. It returns how many were removed.
+func purgeAudited(ctx context.Context, base *BaseRepository, table, entity string, cutoff time.Time, limit int) (int64, error) {
+	return base.ExecAffected(ctx,
+		`WITH purged AS (
+			DELETE FROM `+table+` WHERE id IN (
+				SELECT id FROM `+table+` WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2)
+			RETURNING id)
+		 INSERT INTO audit_log (entity, entity_id, action, request_id, created_at)
+		 SELECT $3::text, id, $4::text, NULLIF($5::text, ''), $6::timestamptz FROM purged`,
+		cutoff, limit, entity, auditPurge, logger.RequestID(ctx), time.Now().UTC(),
+	)
+}
+
+// diffFields compares two records field by field using their JSON form and returns
+// the fields that differ. A nil before or after stands for "did not exist".
+func diffFields(before, after interface{}) (map[string]model.FieldChange, error) {
+	old, err := fieldValues(before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := fieldValues(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]model.FieldChange{}
+	for name, v := range old {
+		if !auditIgnored[name] && !reflect.DeepEqual(v, cur[name]) {
+			changes[name] = model.FieldChange{Old: v, New: cur[name]}
+		}
+	}
+	for name, v := range cur {
+		if _, seen := old[name]; !seen && !auditIgnored[name] {
+			changes[name] = model.FieldChange{New: v}
+		}
+	}
+	return changes, nil
+}
+
+// fieldValues returns v's JSON object as a map; empty values left out by omitempty
+// are simply absent.
+func fieldValues(v interface{}) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return values, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return values, json.Unmarshal(b, &values)
+}
@@ -3,57 +3,173 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/pkg/db"
+	"github.com/example/golang-project/pkg/logger"
 )
 
-// Repository is the interface that all repositories must implement.
-// Similar to .NET's IRepository<T>, but tailored for Go's context-first approach.
-type Repository interface {
+// Executor runs statements for a repository; BaseRepository implements it, and
+// Repository[T] builds its statements on top of it.
+type Executor interface {
 	// ScanRow executes a SELECT query that returns a single row.
 	// The scanFn callback handles reading the row into a destination.
+	// Outside a transaction the query may run on a read replica.
 	ScanRow(ctx context.Context, query string, scanFn func(*sql.Row) error, args ...interface{}) error
 
 	// ScanRows executes a SELECT query that returns multiple rows.
 	// The scanFn callback iterates through rows and returns any errors.
+	// Outside a transaction the query may run on a read replica.
 	ScanRows(ctx context.Context, query string, scanFn func(*sql.Rows) error, args ...interface{}) error
 
+	// WriteRow is ScanRow for statements that change or lock rows, such as
+	// INSERT ... RETURNING or SELECT ... FOR UPDATE; it always runs on the primary.
+	WriteRow(ctx context.Context, query string, scanFn func(*sql.Row) error, args ...interface{}) error
+
 	// ExecUpdate executes an INSERT, UPDATE, or DELETE query.
 	// Returns error if the operation fails.
 	ExecUpdate(ctx context.Context, query string, args ...interface{}) error
+
+	// ExecAffected executes an UPDATE or DELETE query and returns the number of rows it changed.
+	ExecAffected(ctx context.Context, query string, args ...interface{}) (int64, error)
+}
+
+// ErrVersionMismatch is returned by writes whose expected row version is no longer current,
+// i.e. someone else changed the row since the caller read it.
+var ErrVersionMismatch = errors.New("row version mismatch")
+
+// querier is satisfied by both *sql.DB and *sql.Tx, so the same repository code runs
+// inside or outside a transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
 // BaseRepository is a generic repository implementation that all domain repositories can embed.
 // It provides common database operations (like .NET's Repository<T> base class).
+// Statements run in the transaction carried by ctx (see db.UnitOfWorkImpl.WithTransaction)
+// when there is one. Otherwise ScanRow and ScanRows run where router.Reader says,
+// possibly on a read replica, and everything else on the primary. Which one a statement
+// needs is up to the caller; the SQL text is never inspected.
 type BaseRepository struct {
-	db *sql.DB
+	router *db.Router
+	uow    db.UnitOfWork
+}
+
+// NewBaseRepository creates a new base repository on the pools of router.
+func NewBaseRepository(router *db.Router) *BaseRepository {
+	return &BaseRepository{router: router, uow: db.NewUnitOfWork(router.Primary())}
 }
 
-// NewBaseRepository creates a new base repository with a database connection pool.
-func NewBaseRepository(db *sql.DB) *BaseRepository {
-	return &BaseRepository{db: db}
+// Transaction runs fn in a transaction, passing it a context that carries the
+// transaction. It commits if fn returns nil and rolls back otherwise, and retries
+// serialization failures and deadlocks as db.UnitOfWorkImpl.WithTransaction describes.
+// Inside a caller's transaction fn runs in a savepoint of it instead, and the caller
+// decides the outcome.
+func (br *BaseRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...db.TxOption) error {
+	return br.uow.WithTransaction(ctx, fn, opts...)
+}
+
+// reader returns the transaction carried by ctx, or the pool router.Reader picks.
+func (br *BaseRepository) reader(ctx context.Context) querier {
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return br.router.Reader(ctx)
+}
+
+// writer returns the transaction carried by ctx, or the primary, and marks the request
+// as having written so its later reads see the change (see db.WithSession).
+func (br *BaseRepository) writer(ctx context.Context) querier {
+	db.MarkWrite(ctx)
+	if tx := db.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return br.router.Primary()
 }
 
 // ScanRow executes a SELECT query and scans a single row using the provided scanFn.
 // This avoids repeating r.db.QueryRowContext(...).Scan(...) boilerplate in each repo.
 // The scanFn callback is responsible for reading the row data.
 func (br *BaseRepository) ScanRow(ctx context.Context, query string, scanFn func(*sql.Row) error, args ...interface{}) error {
-	return scanFn(br.db.QueryRowContext(ctx, query, args...))
+	start := time.Now()
+	err := translate(scanFn(br.reader(ctx).QueryRowContext(ctx, query, args...)))
+	logQuery(ctx, query, start, err)
+	return err
+}
+
+// WriteRow executes a statement that changes or locks rows on the primary (or the
+// transaction carried by ctx) and scans the single row it returns using scanFn.
+func (br *BaseRepository) WriteRow(ctx context.Context, query string, scanFn func(*sql.Row) error, args ...interface{}) error {
+	start := time.Now()
+	err := translate(scanFn(br.writer(ctx).QueryRowContext(ctx, query, args...)))
+	logQuery(ctx, query, start, err)
+	return err
 }
 
 // ExecUpdate executes an INSERT, UPDATE, or DELETE query.
 // This avoids repeating r.db.ExecContext(...) boilerplate in each repo.
 func (br *BaseRepository) ExecUpdate(ctx context.Context, query string, args ...interface{}) error {
-	_, err := br.db.ExecContext(ctx, query, args...)
+	start := time.Now()
+	_, err := br.writer(ctx).ExecContext(ctx, query, args...)
+	err = translate(err)
+	logQuery(ctx, query, start, err)
 	return err
 }
 
+// ExecAffected executes an UPDATE or DELETE query and returns the number of rows it changed,
+// for callers that need to tell "nothing matched" apart from success.
+func (br *BaseRepository) ExecAffected(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	start := time.Now()
+	res, err := br.writer(ctx).ExecContext(ctx, query, args...)
+	err = translate(err)
+	logQuery(ctx, query, start, err)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // ScanRows executes a SELECT query that returns multiple rows and iterates using the provided scanFn.
 // This avoids repeating QueryContext + defer Close boilerplate.
 // The scanFn callback is responsible for iterating rows.Next() and scanning each row.
 func (br *BaseRepository) ScanRows(ctx context.Context, query string, scanFn func(*sql.Rows) error, args ...interface{}) error {
-	rows, err := br.db.QueryContext(ctx, query, args...)
+	start := time.Now()
+	rows, err := br.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
+		err = translate(err)
+		logQuery(ctx, query, start, err)
 		return err
 	}
 	defer rows.Close()
-	return scanFn(rows)
+	err = translate(scanFn(rows))
+	logQuery(ctx, query, start, err)
+	return err
+}
+
+// logQuery writes one debug line per statement through the request-scoped logger,
+// escalating to error level for failures other than sql.ErrNoRows and the client errors
+// produced by translate, which are logged when the request is rejected.
+func logQuery(ctx context.Context, query string, start time.Time, err error) {
+	log := logger.FromContext(ctx)
+	level := slog.LevelDebug
+	if err != nil && !errors.Is(err, sql.ErrNoRows) && apperror.As(err).Kind == apperror.KindInternal {
+		level = slog.LevelError
+	}
+	if !log.Enabled(ctx, level) {
+		return
+	}
+	attrs := []any{
+		"query", strings.Join(strings.Fields(query), " "),
+		"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	log.Log(ctx, level, "db query", attrs...)
 }
@@ -3,131 +3,405 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/pkg/db"
 )
 
 // ChurchMemberRepository provides CRUD access to church members in Postgres.
+// Deleted members stay in the table with deleted_at set; every read except the trash
+// methods skips them. Every write also appends to the audit trail in the same transaction.
 type ChurchMemberRepository struct {
-	base *BaseRepository
+	base  *BaseRepository
+	live  *Repository[model.ChurchMember] // members not in the trash
+	trash *Repository[model.ChurchMember]
 }
 
-// NewChurchMemberRepository creates a new church member repository with a DB handle.
-func NewChurchMemberRepository(db *sql.DB) *ChurchMemberRepository {
-	return &ChurchMemberRepository{base: NewBaseRepository(db)}
+// NewChurchMemberRepository creates a new church member repository on the pools of router.
+func NewChurchMemberRepository(router *db.Router) *ChurchMemberRepository {
+	base := NewBaseRepository(router)
+	members := NewRepository[model.ChurchMember](base, "church_members")
+	return &ChurchMemberRepository{
+		base:  base,
+		live:  members.Scope("deleted_at IS NULL"),
+		trash: members.Scope("deleted_at IS NOT NULL"),
+	}
 }
 
-// Create inserts a new church member and returns the new ID.
+// Create inserts a new church member and returns the new ID; m.ID, m.Version and the
+// timestamps are set to the stored values.
 func (r *ChurchMemberRepository) Create(ctx context.Context, m *model.ChurchMember) (int64, error) {
 	now := time.Now().UTC()
-	var id int64
-	err := r.base.ScanRow(ctx,
-		`INSERT INTO church_members (name, email, phone, address, biography, joined_at, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
-		func(row *sql.Row) error {
-			return row.Scan(&id)
-		},
-		m.Name, m.Email, m.Phone, m.Address, m.Biography, m.JoinedAt, now, now,
-	)
-	return id, err
+	m.CreatedAt, m.UpdatedAt, m.DeletedAt = now, now, nil
+	err := r.base.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.live.Insert(ctx, m); err != nil {
+			return err
+		}
+		return r.audit(ctx, m.ID, auditCreate, nil, m)
+	})
+	return m.ID, err
 }
 
 // GetByID returns a single church member by ID.
 func (r *ChurchMemberRepository) GetByID(ctx context.Context, id int64) (*model.ChurchMember, error) {
-	var m model.ChurchMember
-	err := r.base.ScanRow(ctx,
-		`SELECT id, name, email, phone, address, biography, joined_at, created_at, updated_at
-		 FROM church_members WHERE id = $1`,
-		func(row *sql.Row) error {
-			return row.Scan(&m.ID, &m.Name, &m.Email, &m.Phone, &m.Address, &m.Biography, &m.JoinedAt, &m.CreatedAt, &m.UpdatedAt)
-		},
-		id,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &m, nil
+	return r.live.Get(ctx, id)
 }
 
 // GetByEmail returns a church member by email.
 func (r *ChurchMemberRepository) GetByEmail(ctx context.Context, email string) (*model.ChurchMember, error) {
-	var m model.ChurchMember
+	return r.live.GetBy(ctx, "email", email)
+}
+
+// Update modifies an existing church member's information. A non-zero m.Version must
+// match the stored version or ErrVersionMismatch is returned; on success m.Version is
+// set to the new version.
+func (r *ChurchMemberRepository) Update(ctx context.Context, m *model.ChurchMember) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, m.ID)
+		if err != nil || before == nil {
+			return err
+		}
+		if m.Version != 0 && m.Version != before.Version {
+			return ErrVersionMismatch
+		}
+		after := *before
+		after.Name, after.Email, after.Phone, after.Address, after.Biography = m.Name, m.Email, m.Phone, m.Address, m.Biography
+		after.UpdatedAt = time.Now().UTC()
+		if _, err := r.live.Update(ctx, &after, "name", "email", "phone", "address", "biography", "updated_at"); err != nil {
+			return err
+		}
+		m.Version = after.Version
+		return r.audit(ctx, m.ID, auditUpdate, before, &after)
+	})
+}
+
+// Patch writes only the columns in which m differs from the stored member: name, email,
+// phone, address, biography and joined_at. Version checking is as in Update. When nothing
+// changed no row is written and the version stays the same; either way m.Version and
+// m.UpdatedAt are set to the stored values afterwards.
+func (r *ChurchMemberRepository) Patch(ctx context.Context, m *model.ChurchMember) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, m.ID)
+		if err != nil || before == nil {
+			return err
+		}
+		if m.Version != 0 && m.Version != before.Version {
+			return ErrVersionMismatch
+		}
+		after := *before
+		after.Name, after.Email, after.Phone, after.Address, after.Biography, after.JoinedAt = m.Name, m.Email, m.Phone, m.Address, m.Biography, m.JoinedAt
+		cols := r.live.Changed(before, &after, "name", "email", "phone", "address", "biography", "joined_at")
+		if len(cols) == 0 {
+			m.Version, m.UpdatedAt = before.Version, before.UpdatedAt
+			return nil
+		}
+		after.UpdatedAt = time.Now().UTC()
+		if _, err := r.live.Update(ctx, &after, append(cols, "updated_at")...); err != nil {
+			return err
+		}
+		m.Version, m.UpdatedAt = after.Version, after.UpdatedAt
+		return r.audit(ctx, m.ID, auditUpdate, before, &after)
+	})
+}
+
+// Delete moves a church member to the trash by setting deleted_at. A non-zero version
+// must match the stored version or ErrVersionMismatch is returned.
+func (r *ChurchMemberRepository) Delete(ctx context.Context, id, version int64) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if version != 0 && version != before.Version {
+			return ErrVersionMismatch
+		}
+		now := time.Now().UTC()
+		after := *before
+		after.DeletedAt = &now
+		if _, err := r.live.Update(ctx, &after, "deleted_at"); err != nil {
+			return err
+		}
+		return r.audit(ctx, id, auditDelete, before, &after)
+	})
+}
+
+// GetDeleted returns a church member from the trash, or nil, nil if id is not in the trash.
+func (r *ChurchMemberRepository) GetDeleted(ctx context.Context, id int64) (*model.ChurchMember, error) {
+	return r.trash.Get(ctx, id)
+}
+
+// Restore takes a church member out of the trash. A redirect left by merging the member
+// into another is dropped, so the ID resolves to the member itself again.
+func (r *ChurchMemberRepository) Restore(ctx context.Context, id int64) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetDeleted(ctx, id)
+		if err != nil || before == nil {
+			return err
+		}
+		after := *before
+		after.DeletedAt, after.UpdatedAt = nil, time.Now().UTC()
+		if _, err := r.trash.Update(ctx, &after, "deleted_at", "updated_at"); err != nil {
+			return err
+		}
+		if err := r.base.ExecUpdate(ctx,
+			`DELETE FROM member_redirects WHERE old_id=$1`,
+			id,
+		); err != nil {
+			return err
+		}
+		return r.audit(ctx, id, auditRestore, before, &after)
+	})
+}
+
+// ListTrash returns one page of deleted church members, most recently deleted first.
+func (r *ChurchMemberRepository) ListTrash(ctx context.Context, page model.PageRequest) (*model.Page[*model.ChurchMember], error) {
+	return paginate(ctx, r.base, keyset{
+		columns: r.trash.columnList(),
+		from:    "church_members",
+		where:   []string{"deleted_at IS NOT NULL"},
+		keys:    trashKeys,
+	}, page, r.trash.scan, func(m *model.ChurchMember) []interface{} {
+		return []interface{}{*m.DeletedAt, m.ID}
+	})
+}
+
+// Purge permanently deletes up to limit members that were moved to the trash before
+// cutoff and returns how many it removed. Callers repeat it until fewer than limit come
+// back, which keeps each statement (and the locks it holds) short.
+func (r *ChurchMemberRepository) Purge(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return purgeAudited(ctx, r.base, "church_members", auditMember, cutoff, limit)
+}
+
+// audit records the fields that differ between before and after in the audit trail;
+// ctx should carry the transaction making the change.
+func (r *ChurchMemberRepository) audit(ctx context.Context, id int64, action string, before, after *model.ChurchMember) error {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, r.base, auditMember, id, action, changes)
+}
+
+// GetForUpdate returns a church member by ID and locks the row until the surrounding
+// transaction ends. It returns nil, nil when the member does not exist.
+func (r *ChurchMemberRepository) GetForUpdate(ctx context.Context, id int64) (*model.ChurchMember, error) {
+	return r.live.GetForUpdate(ctx, id)
+}
+
+// UpdateJoinedAt changes when a member joined (Update leaves joined_at alone).
+func (r *ChurchMemberRepository) UpdateJoinedAt(ctx context.Context, id int64, joinedAt time.Time) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, id)
+		if err != nil || before == nil {
+			return err
+		}
+		after := *before
+		after.JoinedAt = joinedAt
+		if _, err := r.live.Update(ctx, &after, "joined_at"); err != nil {
+			return err
+		}
+		return r.audit(ctx, id, auditUpdate, before, &after)
+	})
+}
+
+// AddRedirect records that oldID was merged into newID. Redirects that pointed at oldID
+// are moved to newID so chains of merges resolve in one step.
+func (r *ChurchMemberRepository) AddRedirect(ctx context.Context, oldID, newID int64) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.base.ExecUpdate(ctx,
+			`UPDATE member_redirects SET new_id=$1 WHERE new_id=$2`,
+			newID, oldID,
+		); err != nil {
+			return err
+		}
+		if err := r.base.ExecUpdate(ctx,
+			`INSERT INTO member_redirects (old_id, new_id, merged_at) VALUES ($1, $2, $3)`,
+			oldID, newID, time.Now().UTC(),
+		); err != nil {
+			return err
+		}
+		return recordAudit(ctx, r.base, auditMember, oldID, auditMerge, map[string]model.FieldChange{
+			"merged_into": {New: newID},
+		})
+	})
+}
+
+// RedirectFor returns the member that id was merged into, or 0 if there is none.
+func (r *ChurchMemberRepository) RedirectFor(ctx context.Context, id int64) (int64, error) {
+	var newID int64
 	err := r.base.ScanRow(ctx,
-		`SELECT id, name, email, phone, address, biography, joined_at, created_at, updated_at
-		 FROM church_members WHERE email = $1`,
+		`SELECT new_id FROM member_redirects WHERE old_id = $1`,
 		func(row *sql.Row) error {
-			return row.Scan(&m.ID, &m.Name, &m.Email, &m.Phone, &m.Address, &m.Biography, &m.JoinedAt, &m.CreatedAt, &m.UpdatedAt)
+			return row.Scan(&newID)
 		},
-		email,
+		id,
 	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
+	if err == sql.ErrNoRows {
+		return 0, nil
 	}
-	return &m, nil
+	return newID, err
 }
 
-// Update modifies an existing church member's information.
-func (r *ChurchMemberRepository) Update(ctx context.Context, m *model.ChurchMember) error {
-	now := time.Now().UTC()
-	return r.base.ExecUpdate(ctx,
-		`UPDATE church_members SET name=$1, email=$2, phone=$3, address=$4, biography=$5, updated_at=$6
-		 WHERE id=$7`,
-		m.Name, m.Email, m.Phone, m.Address, m.Biography, now, m.ID,
-	)
+// ListAll returns every live church member ordered by id. Use it only for whole-table
+// work such as duplicate detection; API listings go through List.
+func (r *ChurchMemberRepository) ListAll(ctx context.Context) ([]*model.ChurchMember, error) {
+	return r.live.List(ctx, "")
 }
 
-// Delete removes a church member by ID.
-func (r *ChurchMemberRepository) Delete(ctx context.Context, id int64) error {
-	return r.base.ExecUpdate(ctx,
-		`DELETE FROM church_members WHERE id=$1`,
-		id,
-	)
+// memberKeys is the default order: newest first, with id breaking ties between equal join dates.
+var memberKeys = []keyColumn{{name: "joined_at", kind: keyTime, desc: true}, {name: "id", kind: keyInt, desc: true}}
+
+// trashKeys orders the trash: most recently deleted first.
+var trashKeys = []keyColumn{{name: "deleted_at", kind: keyTime, desc: true}, {name: "id", kind: keyInt, desc: true}}
+
+// memberFields whitelists what List may filter and sort on.
+var memberFields = map[string]fieldSpec{
+	"id":         {column: "id", kind: keyInt, ops: rangeOps, sortable: true},
+	"name":       {column: "name", kind: keyString, ops: textOps, sortable: true},
+	"email":      {column: "email", kind: keyString, ops: textOps, sortable: true},
+	"phone":      {column: "phone", kind: keyString, ops: optionalOps},
+	"address":    {column: "address", kind: keyString, ops: optionalOps},
+	"biography":  {column: "biography", kind: keyString, ops: []string{opContains, opExists}},
+	"joined_at":  {column: "joined_at", kind: keyTime, ops: rangeOps, sortable: true},
+	"created_at": {column: "created_at", kind: keyTime, ops: rangeOps, sortable: true},
+	"updated_at": {column: "updated_at", kind: keyTime, ops: rangeOps, sortable: true},
 }
 
-// List returns all church members, ordered by joined_at (newest first).
-func (r *ChurchMemberRepository) List(ctx context.Context) ([]*model.ChurchMember, error) {
-	var members []*model.ChurchMember
-	err := r.base.ScanRows(ctx,
-		`SELECT id, name, email, phone, address, biography, joined_at, created_at, updated_at
-		 FROM church_members ORDER BY joined_at DESC`,
-		func(rows *sql.Rows) error {
-			for rows.Next() {
-				var m model.ChurchMember
-				if err := rows.Scan(&m.ID, &m.Name, &m.Email, &m.Phone, &m.Address, &m.Biography, &m.JoinedAt, &m.CreatedAt, &m.UpdatedAt); err != nil {
-					return err
-				}
-				members = append(members, &m)
+// memberKeyFunc returns the values of m for keys, matching them by column name.
+func memberKeyFunc(keys []keyColumn) func(*model.ChurchMember) []interface{} {
+	return func(m *model.ChurchMember) []interface{} {
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			switch k.name {
+			case "id":
+				values[i] = m.ID
+			case "name":
+				values[i] = m.Name
+			case "email":
+				values[i] = m.Email
+			case "joined_at":
+				values[i] = m.JoinedAt
+			case "created_at":
+				values[i] = m.CreatedAt
+			case "updated_at":
+				values[i] = m.UpdatedAt
 			}
-			return rows.Err()
-		},
-	)
-	return members, err
+		}
+		return values
+	}
 }
 
-// ListByJoinedDateRange returns church members joined within a date range.
-func (r *ChurchMemberRepository) ListByJoinedDateRange(ctx context.Context, startDate, endDate time.Time) ([]*model.ChurchMember, error) {
-	var members []*model.ChurchMember
-	err := r.base.ScanRows(ctx,
-		`SELECT id, name, email, phone, address, biography, joined_at, created_at, updated_at
-		 FROM church_members WHERE joined_at >= $1 AND joined_at <= $2 ORDER BY joined_at DESC`,
+// List returns one page of church members matching q, ordered by q.Sort or by
+// joined_at (newest first). Unknown fields, operators or values yield a validation error.
+func (r *ChurchMemberRepository) List(ctx context.Context, q model.ListQuery, page model.PageRequest) (*model.Page[*model.ChurchMember], error) {
+	where, args, err := buildFilters(memberFields, q.Filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := buildSort(memberFields, q.Sort, memberKeys)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(ctx, r.base, keyset{
+		columns: r.live.columnList(),
+		from:    "church_members",
+		where:   append([]string{"deleted_at IS NULL"}, where...),
+		args:    args,
+		keys:    keys,
+	}, page, r.live.scan, memberKeyFunc(keys))
+}
+
+// Each calls fn for every live church member matching q, in the order List returns them.
+// Rows are read from the database as fn consumes them, so memory use does not grow with
+// the result; the connection is held until Each returns. It stops at the first error
+// from fn and returns it.
+func (r *ChurchMemberRepository) Each(ctx context.Context, q model.ListQuery, fn func(*model.ChurchMember) error) error {
+	where, args, err := buildFilters(memberFields, q.Filters, nil)
+	if err != nil {
+		return err
+	}
+	keys, err := buildSort(memberFields, q.Sort, memberKeys)
+	if err != nil {
+		return err
+	}
+	where = append([]string{"deleted_at IS NULL"}, where...)
+	return r.base.ScanRows(ctx,
+		`SELECT `+r.live.columnList()+` FROM church_members WHERE `+strings.Join(where, " AND ")+` ORDER BY `+orderBy(keys, false),
 		func(rows *sql.Rows) error {
 			for rows.Next() {
-				var m model.ChurchMember
-				if err := rows.Scan(&m.ID, &m.Name, &m.Email, &m.Phone, &m.Address, &m.Biography, &m.JoinedAt, &m.CreatedAt, &m.UpdatedAt); err != nil {
+				m, err := r.live.scan(rows)
+				if err != nil {
+					return err
+				}
+				if err := fn(m); err != nil {
 					return err
 				}
-				members = append(members, &m)
 			}
 			return rows.Err()
 		},
-		startDate, endDate,
+		args...,
 	)
-	return members, err
+}
+
+// searchTerm matches the words kept from a search query; everything else is dropped so
+// user input can never form tsquery operators.
+var searchTerm = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// maxSearchTerms bounds the size of the generated tsquery.
+const maxSearchTerms = 10
+
+// prefixQuery turns free text into a tsquery string matching every word as a prefix,
+// e.g. "Jo smi" -> "jo:* & smi:*". It returns "" when the text has no words.
+func prefixQuery(text string) string {
+	terms := searchTerm.FindAllString(strings.ToLower(text), maxSearchTerms)
+	for i, t := range terms {
+		terms[i] = t + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// Search returns one page of members matching text across name, email, address and
+// biography, best matches first. Every word is matched as a prefix.
+func (r *ChurchMemberRepository) Search(ctx context.Context, text string, page model.PageRequest) (*model.Page[*model.MemberSearchHit], error) {
+	tsquery := prefixQuery(text)
+	if tsquery == "" {
+		limit := page.Limit
+		if limit <= 0 {
+			limit = model.DefaultPageLimit
+		}
+		return &model.Page[*model.MemberSearchHit]{Items: []*model.MemberSearchHit{}, Limit: limit}, nil
+	}
+
+	// Names are indexed without stemming and the rest as English, so the query is built
+	// both ways. rank is cast to float8 so it round-trips through page cursors exactly.
+	hits := `(SELECT m.*, q.query, ts_rank(m.search_vector, q.query)::float8 AS rank
+		FROM church_members m,
+		     (SELECT to_tsquery('simple', $1) || to_tsquery('english', $1) AS query) q
+		WHERE m.search_vector @@ q.query AND m.deleted_at IS NULL) AS hits`
+
+	const headline = `'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" ... "'`
+	columns := r.live.columnList() + `, rank,
+		ts_headline('simple', name, query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+		CASE WHEN to_tsvector('english', coalesce(address, '')) @@ query
+		     THEN ts_headline('english', address, query, ` + headline + `) ELSE '' END,
+		CASE WHEN to_tsvector('english', coalesce(biography, '')) @@ query
+		     THEN ts_headline('english', biography, query, ` + headline + `) ELSE '' END`
+
+	return paginate(ctx, r.base, keyset{
+		columns: columns,
+		from:    hits,
+		args:    []interface{}{tsquery},
+		keys:    []keyColumn{{name: "rank", kind: keyFloat, desc: true}, {name: "id", kind: keyInt, desc: true}},
+		scope:   tsquery,
+	}, page, func(rows *sql.Rows) (*model.MemberSearchHit, error) {
+		var h model.MemberSearchHit
+		dest := append(r.live.dest(&h.ChurchMember), &h.Rank, &h.Highlight.Name, &h.Highlight.Address, &h.Highlight.Biography)
+		err := rows.Scan(dest...)
+		return &h, err
+	}, func(h *model.MemberSearchHit) []interface{} {
+		return []interface{}{h.Rank, h.ID}
+	})
 }
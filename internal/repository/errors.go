@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/lib/pq"
+
+	"github.com/example/golang-project/internal/apperror"
+)
+
+// Postgres failures that BaseRepository translates into typed errors. Compare with
+// errors.Is; errors.As with *ConstraintError gives the table, constraint and column.
+var (
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrNotNullViolation     = errors.New("not null violation")
+	ErrCheckViolation       = errors.New("check violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+)
+
+// Error codes of translated database errors. Services may replace them with a more
+// specific code (e.g. EMAIL_TAKEN) where they know what the constraint means.
+const (
+	CodeDuplicate        = "DUPLICATE_VALUE"
+	CodeReference        = "REFERENCE_VIOLATION"
+	CodeConcurrentUpdate = "CONCURRENT_UPDATE"
+)
+
+// constraintColumns names the column of constraints whose error has no column of its own.
+var constraintColumns = map[string]string{
+	"users_role_check": "role",
+}
+
+// keyDetail extracts the column list from a detail like "Key (email)=(a@b.c) already exists.".
+var keyDetail = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// ConstraintError is a statement rejected by a database constraint. Kind is one of the
+// Err*Violation sentinels above, and errors.Is(err, Kind) holds.
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Constraint string
+	Column     string // empty when Postgres does not say
+	Err        *pq.Error
+}
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%v on %s (constraint %s, column %s): %v", e.Kind, e.Table, e.Constraint, e.Column, e.Err)
+}
+
+// Is reports whether target is e's Kind.
+func (e *ConstraintError) Is(target error) bool { return target == e.Kind }
+
+// Unwrap returns the driver error.
+func (e *ConstraintError) Unwrap() error { return e.Err }
+
+// translate turns the Postgres errors a client can cause into apperror values, so they
+// reach the caller as 409 or 400 with the offending field instead of a 500. The
+// *ConstraintError (or ErrSerializationFailure) stays reachable through errors.As and
+// errors.Is. Other errors are returned unchanged.
+func translate(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	ce := &ConstraintError{Table: pqErr.Table, Constraint: pqErr.Constraint, Column: pqErr.Column, Err: pqErr}
+	if ce.Column == "" {
+		if m := keyDetail.FindStringSubmatch(pqErr.Detail); m != nil {
+			ce.Column = m[1]
+		} else {
+			ce.Column = constraintColumns[pqErr.Constraint]
+		}
+	}
+	field := func(code, message string) []apperror.FieldError {
+		return []apperror.FieldError{{Field: ce.Column, Code: code, Message: message}}
+	}
+
+	var out *apperror.Error
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		ce.Kind = ErrUniqueViolation
+		out = apperror.Wrap(ce, apperror.KindConflict, CodeDuplicate, ce.Column+" already exists")
+		out.Fields = field("unique", ce.Column+" already exists")
+	case "foreign_key_violation":
+		ce.Kind = ErrForeignKeyViolation
+		out = apperror.Wrap(ce, apperror.KindConflict, CodeReference, "the record is missing or still in use")
+		out.Fields = field("reference", ce.Column+" refers to a record that is missing or still in use")
+	case "not_null_violation":
+		ce.Kind = ErrNotNullViolation
+		out = apperror.Wrap(ce, apperror.KindValidation, apperror.CodeValidation, "validation failed")
+		out.Fields = field("required", ce.Column+" is required")
+	case "check_violation":
+		ce.Kind = ErrCheckViolation
+		out = apperror.Wrap(ce, apperror.KindValidation, apperror.CodeValidation, "validation failed")
+		out.Fields = field("check", ce.Column+" is not an allowed value")
+	case "serialization_failure":
+		return apperror.Wrap(fmt.Errorf("%w: %w", ErrSerializationFailure, pqErr), apperror.KindConflict,
+			CodeConcurrentUpdate, "the request conflicted with a concurrent change; try again")
+	default:
+		return err
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/example/golang-project/internal/apperror"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        *pq.Error
+		wantKind   apperror.Kind
+		wantCode   string
+		wantIs     error  // sentinel reachable through errors.Is
+		wantField  string // column in the field error, "" for none
+		wantFieldC string // code of the field error
+	}{
+		{"unique with detail",
+			&pq.Error{Code: "23505", Table: "church_members", Constraint: "church_members_email_key", Detail: "Key (email)=(a@b.c) already exists."},
+			apperror.KindConflict, CodeDuplicate, ErrUniqueViolation, "email", "unique"},
+		{"unique with column",
+			&pq.Error{Code: "23505", Constraint: "users_email_key", Column: "email"},
+			apperror.KindConflict, CodeDuplicate, ErrUniqueViolation, "email", "unique"},
+		{"unique on several columns",
+			&pq.Error{Code: "23505", Constraint: "idempotency_keys_pkey", Detail: "Key (user_id, key)=(1, abc) already exists."},
+			apperror.KindConflict, CodeDuplicate, ErrUniqueViolation, "user_id, key", "unique"},
+		{"foreign key",
+			&pq.Error{Code: "23503", Constraint: "member_redirects_member_id_fkey", Detail: `Key (member_id)=(9) is not present in table "church_members".`},
+			apperror.KindConflict, CodeReference, ErrForeignKeyViolation, "member_id", "reference"},
+		{"not null",
+			&pq.Error{Code: "23502", Table: "church_members", Column: "name"},
+			apperror.KindValidation, apperror.CodeValidation, ErrNotNullViolation, "name", "required"},
+		{"check with known constraint",
+			&pq.Error{Code: "23514", Constraint: "users_role_check"},
+			apperror.KindValidation, apperror.CodeValidation, ErrCheckViolation, "role", "check"},
+		{"check with unknown constraint",
+			&pq.Error{Code: "23514", Constraint: "something_check"},
+			apperror.KindValidation, apperror.CodeValidation, ErrCheckViolation, "", "check"},
+		{"serialization failure",
+			&pq.Error{Code: "40001"},
+			apperror.KindConflict, CodeConcurrentUpdate, ErrSerializationFailure, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, in := range []error{tt.err, fmt.Errorf("insert member: %w", tt.err)} {
+				err := translate(in)
+
+				var appErr *apperror.Error
+				if !errors.As(err, &appErr) {
+					t.Fatalf("translate(%v) = %T, want *apperror.Error", in, err)
+				}
+				if appErr.Kind != tt.wantKind || appErr.Code != tt.wantCode {
+					t.Errorf("kind %q, code %q; want %q, %q", appErr.Kind, appErr.Code, tt.wantKind, tt.wantCode)
+				}
+				if !errors.Is(err, tt.wantIs) {
+					t.Errorf("errors.Is(err, %v) = false", tt.wantIs)
+				}
+				var pqErr *pq.Error
+				if !errors.As(err, &pqErr) || pqErr != tt.err {
+					t.Errorf("errors.As(err, *pq.Error) lost the driver error")
+				}
+				if tt.wantFieldC == "" {
+					if len(appErr.Fields) != 0 {
+						t.Errorf("fields = %+v, want none", appErr.Fields)
+					}
+					continue
+				}
+				if len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.wantField || appErr.Fields[0].Code != tt.wantFieldC {
+					t.Errorf("fields = %+v, want one %q error on %q", appErr.Fields, tt.wantFieldC, tt.wantField)
+				}
+				var ce *ConstraintError
+				if !errors.As(err, &ce) || ce.Kind != tt.wantIs || ce.Constraint != tt.err.Constraint {
+					t.Errorf("errors.As(err, *ConstraintError) = %+v", ce)
+				}
+			}
+		})
+	}
+}
+
+// TestTranslateLeavesRetryableErrorsReachable guards what db.WithTransaction relies on:
+// after translation a serialization failure or deadlock still unwraps to its *pq.Error,
+// so the unit of work can be retried.
+func TestTranslateLeavesRetryableErrorsReachable(t *testing.T) {
+	for _, code := range []pq.ErrorCode{"40001", "40P01"} {
+		t.Run(string(code), func(t *testing.T) {
+			err := translate(fmt.Errorf("update member: %w", &pq.Error{Code: code}))
+			var pqErr *pq.Error
+			if !errors.As(err, &pqErr) || pqErr.Code != code {
+				t.Errorf("errors.As(%v, *pq.Error) = %v, want code %s", err, pqErr, code)
+			}
+		})
+	}
+	// Deadlocks are not translated at all.
+	deadlock := &pq.Error{Code: "40P01"}
+	if err := translate(deadlock); err != error(deadlock) {
+		t.Errorf("translate(deadlock) = %v, want the error unchanged", err)
+	}
+}
+
+func TestTranslatePassesOtherErrorsThrough(t *testing.T) {
+	other := &pq.Error{Code: "42P01"} // undefined_table
+	wrapped := fmt.Errorf("list: %w", other)
+	plain := errors.New("boom")
+	for _, err := range []error{nil, sql.ErrNoRows, plain, other, wrapped} {
+		if got := translate(err); got != err {
+			t.Errorf("translate(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/model"
+)
+
+// maxFilters bounds how many conditions one list request may combine.
+const maxFilters = 20
+
+// Filter operators. Not every field supports every operator; see fieldSpec.ops.
+const (
+	opEq       = "eq"
+	opNe       = "ne"
+	opGt       = "gt"
+	opGte      = "gte"
+	opLt       = "lt"
+	opLte      = "lte"
+	opPrefix   = "prefix"   // case-insensitive "starts with"
+	opSuffix   = "suffix"   // case-insensitive "ends with", e.g. an email domain
+	opContains = "contains" // case-insensitive substring
+	opExists   = "exists"   // true: set and non-empty, false: NULL or empty
+)
+
+var (
+	textOps     = []string{opEq, opNe, opPrefix, opSuffix, opContains}
+	optionalOps = []string{opEq, opNe, opPrefix, opSuffix, opContains, opExists}
+	rangeOps    = []string{opEq, opNe, opGt, opGte, opLt, opLte}
+)
+
+// fieldSpec whitelists one filterable or sortable field of a list. Only names found in a
+// spec map ever reach SQL; values are always passed as parameters.
+type fieldSpec struct {
+	column   string
+	kind     keyKind
+	ops      []string
+	sortable bool // sortable columns must be NOT NULL so keyset comparisons hold
+}
+
+var comparisons = map[string]string{opEq: "=", opNe: "<>", opGt: ">", opGte: ">=", opLt: "<", opLte: "<="}
+
+// likeEscaper escapes LIKE wildcards in user input (the default escape character is \).
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// buildFilters translates q's filters into SQL conditions using fields as the whitelist.
+// Parameters are numbered after the len(args) already present. All problems are reported
+// together as a validation error.
+func buildFilters(fields map[string]fieldSpec, filters []model.Filter, args []interface{}) ([]string, []interface{}, error) {
+	var where []string
+	var problems []apperror.FieldError
+	if len(filters) > maxFilters {
+		problems = append(problems, apperror.FieldError{
+			Field: "filter", Code: "too_many", Message: "at most " + strconv.Itoa(maxFilters) + " filters are allowed",
+		})
+		filters = filters[:maxFilters]
+	}
+
+	for _, f := range filters {
+		name := "filter[" + f.Field + "][" + f.Op + "]"
+		spec, ok := fields[f.Field]
+		if !ok {
+			problems = append(problems, apperror.FieldError{
+				Field: name, Code: "unknown_field",
+				Message: "cannot filter on " + strconv.Quote(f.Field) + "; allowed fields: " + strings.Join(filterableFields(fields), ", "),
+			})
+			continue
+		}
+		if !contains(spec.ops, f.Op) {
+			problems = append(problems, apperror.FieldError{
+				Field: name, Code: "unknown_operator",
+				Message: "operator " + strconv.Quote(f.Op) + " is not supported for " + f.Field + "; allowed: " + strings.Join(spec.ops, ", "),
+			})
+			continue
+		}
+
+		param := func(v interface{}) string {
+			args = append(args, v)
+			return "$" + strconv.Itoa(len(args))
+		}
+
+		switch f.Op {
+		case opExists:
+			set, err := strconv.ParseBool(f.Value)
+			if err != nil {
+				problems = append(problems, invalidValue(name, "must be true or false"))
+				continue
+			}
+			if set {
+				where = append(where, "COALESCE("+spec.column+", '') <> ''")
+			} else {
+				where = append(where, "COALESCE("+spec.column+", '') = ''")
+			}
+
+		case opPrefix, opSuffix, opContains:
+			pattern := likeEscaper.Replace(f.Value)
+			switch f.Op {
+			case opPrefix:
+				pattern += "%"
+			case opSuffix:
+				pattern = "%" + pattern
+			default:
+				pattern = "%" + pattern + "%"
+			}
+			where = append(where, spec.column+" ILIKE "+param(pattern))
+
+		default:
+			v, err := filterValue(spec.kind, f.Value)
+			if err != nil {
+				msg := "must be a number"
+				if spec.kind == keyTime {
+					msg = "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"
+				}
+				problems = append(problems, invalidValue(name, msg))
+				continue
+			}
+			// Text equality ignores case, like the other text operators.
+			if spec.kind == keyString && (f.Op == opEq || f.Op == opNe) {
+				where = append(where, "LOWER("+spec.column+") "+comparisons[f.Op]+" LOWER("+param(v)+")")
+				continue
+			}
+			where = append(where, spec.column+" "+comparisons[f.Op]+" "+param(v))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, apperror.InvalidFields(problems)
+	}
+	return where, args, nil
+}
+
+// buildSort turns the requested sort into keyset columns, appending id as the tie-breaker.
+// An empty sort falls back to def.
+func buildSort(fields map[string]fieldSpec, sortBy []model.SortField, def []keyColumn) ([]keyColumn, error) {
+	if len(sortBy) == 0 {
+		return def, nil
+	}
+	var keys []keyColumn
+	var problems []apperror.FieldError
+	seen := map[string]bool{}
+	for _, s := range sortBy {
+		spec, ok := fields[s.Field]
+		if !ok || !spec.sortable {
+			problems = append(problems, apperror.FieldError{
+				Field: "sort", Code: "unknown_field",
+				Message: "cannot sort by " + strconv.Quote(s.Field) + "; allowed fields: " + strings.Join(sortableFields(fields), ", "),
+			})
+			continue
+		}
+		if seen[s.Field] {
+			continue
+		}
+		seen[s.Field] = true
+		keys = append(keys, keyColumn{name: spec.column, kind: spec.kind, desc: s.Desc})
+	}
+	if len(problems) > 0 {
+		return nil, apperror.InvalidFields(problems)
+	}
+	if !seen["id"] {
+		keys = append(keys, keyColumn{name: "id", kind: keyInt, desc: keys[len(keys)-1].desc})
+	}
+	return keys, nil
+}
+
+// filterValue parses a comparison value for a column of the given kind.
+func filterValue(kind keyKind, s string) (interface{}, error) {
+	switch kind {
+	case keyInt:
+		return strconv.ParseInt(s, 10, 64)
+	case keyTime:
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", s)
+	}
+	return s, nil
+}
+
+func invalidValue(field, msg string) apperror.FieldError {
+	return apperror.FieldError{Field: field, Code: "invalid_value", Message: field + " " + msg}
+}
+
+func filterableFields(fields map[string]fieldSpec) []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortableFields(fields map[string]fieldSpec) []string {
+	var names []string
+	for name, spec := range fields {
+		if spec.sortable {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Repository gives an entity the everyday statements (Get, List, Insert, Update, Delete
+// and Exists) without hand-written SQL, so a new table needs only a struct and a table
+// name. Columns come from `db` struct tags:
+//
+//	ID      int64  `db:"id,pk"`           // key of Get/Update/Delete/Exists; set by Insert
+//	Name    string `db:"name"`            // an ordinary column
+//	Version int64  `db:"version,version"` // set by the database, bumped by every Update
+//	Secret  string `db:"-"`               // not a column (neither are untagged fields)
+//
+// Untagged embedded structs are searched for tagged fields too. Keys are int64, as for
+// every table in this schema. Statements run through an Executor, so they join the
+// transaction carried by ctx like any other repository statement.
+//
+// A Repository may be narrowed with Scope (e.g. to rows not in the trash); logic beyond
+// single-row reads and writes, such as version checks and auditing, stays in the domain
+// repository that uses it.
+type Repository[T any] struct {
+	exec    Executor
+	table   string
+	scope   []string
+	columns []column
+	byName  map[string]int
+	pk      int
+	version int // -1 when T has no version column
+}
+
+// column is one mapped struct field.
+type column struct {
+	name  string
+	index []int
+}
+
+// NewRepository maps T's tagged fields to the columns of table. It panics if T is not a
+// struct or has no pk column, since that is a programming error found at startup.
+func NewRepository[T any](exec Executor, table string) *Repository[T] {
+	r := &Repository[T]{exec: exec, table: table, byName: map[string]int{}, pk: -1, version: -1}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("repository: %s is not a struct", t))
+	}
+	r.mapFields(t, nil)
+	if r.pk < 0 {
+		panic(fmt.Sprintf("repository: %s has no pk column", t))
+	}
+	return r
+}
+
+// mapFields adds the tagged fields of t, whose index path from T is prefix.
+func (r *Repository[T]) mapFields(t reflect.Type, prefix []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int(nil), prefix...), i)
+		tag, tagged := f.Tag.Lookup("db")
+		if !tagged {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				r.mapFields(f.Type, index)
+			}
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		r.byName[name] = len(r.columns)
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "pk":
+				r.pk = len(r.columns)
+			case "version":
+				r.version = len(r.columns)
+			}
+		}
+		r.columns = append(r.columns, column{name: name, index: index})
+	}
+}
+
+// Scope returns a copy of r whose reads, updates and deletes only see rows matching
+// cond, e.g. "deleted_at IS NULL". cond is SQL and must never contain user input.
+func (r *Repository[T]) Scope(cond string) *Repository[T] {
+	scoped := *r
+	scoped.scope = append(append([]string(nil), r.scope...), cond)
+	return &scoped
+}
+
+// Get returns the row with the given key, or nil, nil if there is none in scope.
+func (r *Repository[T]) Get(ctx context.Context, id int64) (*T, error) {
+	return r.GetBy(ctx, r.columns[r.pk].name, id)
+}
+
+// GetBy returns the row whose column equals value, or nil, nil if there is none in
+// scope. column must be a column name known at compile time, never user input.
+func (r *Repository[T]) GetBy(ctx context.Context, column string, value interface{}) (*T, error) {
+	return r.getWhere(ctx, r.exec.ScanRow, "", column+" = $1", value)
+}
+
+// GetForUpdate returns the row with the given key like Get, and locks it until the
+// surrounding transaction ends.
+func (r *Repository[T]) GetForUpdate(ctx context.Context, id int64) (*T, error) {
+	return r.getWhere(ctx, r.exec.WriteRow, " FOR UPDATE", r.columns[r.pk].name+" = $1", id)
+}
+
+// getWhere selects one row matching cond through scanRow, which is ScanRow for plain
+// reads and WriteRow for locking ones.
+func (r *Repository[T]) getWhere(ctx context.Context, scanRow func(context.Context, string, func(*sql.Row) error, ...interface{}) error,
+	suffix, cond string, args ...interface{}) (*T, error) {
+	v := new(T)
+	err := scanRow(ctx,
+		`SELECT `+r.columnList()+` FROM `+r.table+` WHERE `+r.where(cond)+suffix,
+		func(row *sql.Row) error {
+			return row.Scan(r.dest(v)...)
+		},
+		args...,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// List returns the rows in scope matching cond (all of them when cond is ""), ordered
+// by key. cond may use $1.. placeholders for args; like Scope it must not contain user
+// input. Paged listings go through paginate instead.
+func (r *Repository[T]) List(ctx context.Context, cond string, args ...interface{}) ([]*T, error) {
+	var items []*T
+	err := r.exec.ScanRows(ctx,
+		`SELECT `+r.columnList()+` FROM `+r.table+` WHERE `+r.where(cond)+` ORDER BY `+r.columns[r.pk].name,
+		func(rows *sql.Rows) error {
+			for rows.Next() {
+				v, err := r.scan(rows)
+				if err != nil {
+					return err
+				}
+				items = append(items, v)
+			}
+			return rows.Err()
+		},
+		args...,
+	)
+	return items, err
+}
+
+// Exists reports whether a row with the given key is in scope.
+func (r *Repository[T]) Exists(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	err := r.exec.ScanRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+r.table+` WHERE `+r.where(r.columns[r.pk].name+" = $1")+`)`,
+		func(row *sql.Row) error {
+			return row.Scan(&exists)
+		},
+		id,
+	)
+	return exists, err
+}
+
+// Insert writes v as a new row and sets its key and version to the values the database
+// assigned. Every other mapped column is written, so v must be complete.
+func (r *Repository[T]) Insert(ctx context.Context, v *T) error {
+	rv := reflect.ValueOf(v).Elem()
+	var names, params []string
+	var args []interface{}
+	for i, c := range r.columns {
+		if i == r.pk || i == r.version {
+			continue
+		}
+		args = append(args, rv.FieldByIndex(c.index).Interface())
+		names = append(names, c.name)
+		params = append(params, fmt.Sprintf("$%d", len(args)))
+	}
+	returning, dest := r.generated(rv)
+	return r.exec.WriteRow(ctx,
+		`INSERT INTO `+r.table+` (`+strings.Join(names, ", ")+`) VALUES (`+strings.Join(params, ", ")+`) RETURNING `+returning,
+		func(row *sql.Row) error {
+			return row.Scan(dest...)
+		},
+		args...,
+	)
+}
+
+// Update writes the named columns of v to the row with v's key and, if T has a version
+// column, bumps the version and sets it on v. It reports false when no such row is in
+// scope. Naming the columns keeps fields the caller did not load, such as a password
+// hash, from being overwritten.
+func (r *Repository[T]) Update(ctx context.Context, v *T, columns ...string) (bool, error) {
+	if len(columns) == 0 {
+		return false, fmt.Errorf("repository: update of %s names no columns", r.table)
+	}
+	rv := reflect.ValueOf(v).Elem()
+	sets := make([]string, 0, len(columns)+1)
+	args := make([]interface{}, 0, len(columns)+1)
+	for _, name := range columns {
+		i, ok := r.byName[name]
+		if !ok || i == r.pk || i == r.version {
+			return false, fmt.Errorf("repository: %s.%s cannot be updated", r.table, name)
+		}
+		args = append(args, rv.FieldByIndex(r.columns[i].index).Interface())
+		sets = append(sets, fmt.Sprintf("%s=$%d", name, len(args)))
+	}
+	if r.version >= 0 {
+		name := r.columns[r.version].name
+		sets = append(sets, name+"="+name+"+1")
+	}
+	pk := r.columns[r.pk]
+	args = append(args, rv.FieldByIndex(pk.index).Interface())
+	returning, dest := r.generated(rv)
+	err := r.exec.WriteRow(ctx,
+		`UPDATE `+r.table+` SET `+strings.Join(sets, ", ")+
+			` WHERE `+r.where(fmt.Sprintf("%s=$%d", pk.name, len(args)))+` RETURNING `+returning,
+		func(row *sql.Row) error {
+			return row.Scan(dest...)
+		},
+		args...,
+	)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete permanently removes the row with the given key and reports whether it was in
+// scope. Soft deletes are an Update of the column that marks them.
+func (r *Repository[T]) Delete(ctx context.Context, id int64) (bool, error) {
+	n, err := r.exec.ExecAffected(ctx,
+		`DELETE FROM `+r.table+` WHERE `+r.where(r.columns[r.pk].name+" = $1"),
+		id,
+	)
+	return n > 0, err
+}
+
+// Changed returns those of the named columns in which after differs from before, for
+// updates that write only what changed. Times are compared with time.Time.Equal.
+func (r *Repository[T]) Changed(before, after *T, columns ...string) []string {
+	b, a := reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem()
+	var changed []string
+	for _, name := range columns {
+		i, ok := r.byName[name]
+		if !ok {
+			panic(fmt.Sprintf("repository: %s has no column %s", r.table, name))
+		}
+		c := r.columns[i]
+		old, cur := b.FieldByIndex(c.index).Interface(), a.FieldByIndex(c.index).Interface()
+		if t, ok := old.(time.Time); ok {
+			if !t.Equal(cur.(time.Time)) {
+				changed = append(changed, name)
+			}
+		} else if !reflect.DeepEqual(old, cur) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// columnList returns the mapped columns in the order scan reads them.
+func (r *Repository[T]) columnList() string {
+	names := make([]string, len(r.columns))
+	for i, c := range r.columns {
+		names[i] = c.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// scan reads one row selected with columnList.
+func (r *Repository[T]) scan(rows *sql.Rows) (*T, error) {
+	v := new(T)
+	err := rows.Scan(r.dest(v)...)
+	return v, err
+}
+
+// dest returns pointers to v's mapped fields in columnList order; callers scanning
+// extra columns append their own destinations.
+func (r *Repository[T]) dest(v *T) []interface{} {
+	rv := reflect.ValueOf(v).Elem()
+	dest := make([]interface{}, len(r.columns))
+	for i, c := range r.columns {
+		dest[i] = rv.FieldByIndex(c.index).Addr().Interface()
+	}
+	return dest
+}
+
+// generated returns the RETURNING list of the columns the database assigns (the key and
+// the version) and the fields of rv that receive them.
+func (r *Repository[T]) generated(rv reflect.Value) (string, []interface{}) {
+	names := []string{r.columns[r.pk].name}
+	dest := []interface{}{rv.FieldByIndex(r.columns[r.pk].index).Addr().Interface()}
+	if r.version >= 0 {
+		names = append(names, r.columns[r.version].name)
+		dest = append(dest, rv.FieldByIndex(r.columns[r.version].index).Addr().Interface())
+	}
+	return strings.Join(names, ", "), dest
+}
+
+// where joins the scope conditions and cond (if any) with AND.
+func (r *Repository[T]) where(cond string) string {
+	conds := append([]string(nil), r.scope...)
+	if cond != "" {
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(conds, " AND ")
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/pkg/db"
+)
+
+// IdempotencyRepository stores Idempotency-Keys and the responses they replay.
+type IdempotencyRepository struct {
+	base *BaseRepository
+}
+
+// NewIdempotencyRepository creates a new idempotency repository on the pools of router.
+func NewIdempotencyRepository(router *db.Router) *IdempotencyRepository {
+	return &IdempotencyRepository{base: NewBaseRepository(router)}
+}
+
+// Claim reserves rec's key for a new request. A key is free when it has never been used,
+// has expired, or was claimed before staleBefore by a request that never completed
+// (e.g. the process died). It returns nil, nil when rec was claimed, and otherwise the
+// record already holding the key.
+func (r *IdempotencyRepository) Claim(ctx context.Context, rec *model.IdempotencyRecord, staleBefore time.Time) (*model.IdempotencyRecord, error) {
+	// A concurrent purge can delete the holding row between the two statements; a
+	// second attempt then claims the key.
+	for attempt := 0; attempt < 2; attempt++ {
+		var claimed bool
+		err := r.base.WriteRow(ctx,
+			`INSERT INTO idempotency_keys (user_id, key, fingerprint, created_at, expires_at)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (user_id, key) DO UPDATE
+			    SET fingerprint = EXCLUDED.fingerprint, status = NULL, headers = NULL, body = NULL,
+			        created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+			  WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			     OR (idempotency_keys.status IS NULL AND idempotency_keys.created_at < $6)
+			 RETURNING true`,
+			func(row *sql.Row) error { return row.Scan(&claimed) },
+			rec.UserID, rec.Key, rec.Fingerprint, rec.CreatedAt, rec.ExpiresAt, staleBefore,
+		)
+		if err == nil {
+			return nil, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+		existing, err := r.get(ctx, rec.UserID, rec.Key)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *IdempotencyRepository) get(ctx context.Context, userID int64, key string) (*model.IdempotencyRecord, error) {
+	rec := model.IdempotencyRecord{UserID: userID, Key: key}
+	var status sql.NullInt64
+	var headers []byte
+	err := r.base.ScanRow(ctx,
+		`SELECT fingerprint, status, headers, body, created_at, expires_at
+		 FROM idempotency_keys WHERE user_id = $1 AND key = $2`,
+		func(row *sql.Row) error {
+			return row.Scan(&rec.Fingerprint, &status, &headers, &rec.Body, &rec.CreatedAt, &rec.ExpiresAt)
+		},
+		userID, key,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rec.Status = int(status.Int64)
+	if headers != nil {
+		if err := json.Unmarshal(headers, &rec.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &rec, nil
+}
+
+// Complete stores the response of the request that claimed rec. It does nothing if the
+// claim has since been taken over.
+func (r *IdempotencyRepository) Complete(ctx context.Context, rec *model.IdempotencyRecord) error {
+	headers, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+	return r.base.ExecUpdate(ctx,
+		`UPDATE idempotency_keys SET status = $1, headers = $2, body = $3
+		 WHERE user_id = $4 AND key = $5 AND created_at = $6 AND status IS NULL`,
+		rec.Status, string(headers), rec.Body, rec.UserID, rec.Key, rec.CreatedAt,
+	)
+}
+
+// Release frees the key claimed by rec without storing a response, so the request can be retried.
+func (r *IdempotencyRepository) Release(ctx context.Context, rec *model.IdempotencyRecord) error {
+	return r.base.ExecUpdate(ctx,
+		`DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND created_at = $3 AND status IS NULL`,
+		rec.UserID, rec.Key, rec.CreatedAt,
+	)
+}
+
+// DeleteExpired removes keys that expired before now and returns how many it removed.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return r.base.ExecAffected(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/golang-project/internal/model"
+)
+
+// ErrInvalidCursor is returned when a page cursor cannot be decoded or doesn't fit the list.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// keyKind tells how a keyset column is stored in a cursor.
+type keyKind int
+
+const (
+	keyInt keyKind = iota
+	keyTime
+	keyString
+	keyFloat // must be float8 in SQL so values survive the round trip through a cursor exactly
+)
+
+// keyColumn is one column of a keyset ordering; the last one must be unique (usually id).
+type keyColumn struct {
+	name string
+	kind keyKind
+	desc bool
+}
+
+// keyset describes a SELECT paginated by seeking past the last row seen instead of OFFSET,
+// so every page costs the same regardless of depth and rows don't shift under concurrent inserts.
+type keyset struct {
+	columns string        // selected columns
+	from    string        // table name, or an aliased subquery
+	where   []string      // extra AND conditions, using $1..$n
+	args    []interface{} // arguments for where
+	keys    []keyColumn   // ordering; the sort key of every row must be unique
+	scope   string        // optional, e.g. search terms; cursors only work within the same scope
+}
+
+// signature identifies the ordering so a cursor from one sort can't be replayed against another.
+func (ks keyset) signature() string {
+	parts := make([]string, len(ks.keys))
+	for i, k := range ks.keys {
+		parts[i] = k.name
+		if k.desc {
+			parts[i] = "-" + k.name
+		}
+	}
+	sig := strings.Join(parts, ",")
+	if ks.scope != "" {
+		sum := sha256.Sum256([]byte(ks.scope))
+		sig += "@" + hex.EncodeToString(sum[:8])
+	}
+	return sig
+}
+
+// cursor is the decoded form of a page cursor: the sort key of a boundary row and
+// which way to read from it.
+type cursor struct {
+	Sort   string   `json:"s,omitempty"`
+	Values []string `json:"v"`
+	Prev   bool     `json:"p,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string, ks keyset) (cursor, []interface{}, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.Sort != ks.signature() || len(c.Values) != len(ks.keys) {
+		return c, nil, ErrInvalidCursor
+	}
+	values := make([]interface{}, len(ks.keys))
+	for i, k := range ks.keys {
+		switch k.kind {
+		case keyTime:
+			values[i], err = time.Parse(time.RFC3339Nano, c.Values[i])
+		case keyString:
+			values[i] = c.Values[i]
+		case keyFloat:
+			values[i], err = strconv.ParseFloat(c.Values[i], 64)
+		default:
+			values[i], err = strconv.ParseInt(c.Values[i], 10, 64)
+		}
+		if err != nil {
+			return c, nil, ErrInvalidCursor
+		}
+	}
+	return c, values, nil
+}
+
+// formatKey renders sort key values for a cursor.
+func formatKey(values []interface{}) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case time.Time:
+			out[i] = v.UTC().Format(time.RFC3339Nano)
+		case float64:
+			out[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		default:
+			out[i] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+// seekCondition returns the WHERE clause selecting rows after values in the ordering
+// (before them when back is set), appending the parameters to args.
+func seekCondition(keys []keyColumn, values []interface{}, back bool, args *[]interface{}) string {
+	params := make([]string, len(keys))
+	for i := range keys {
+		*args = append(*args, values[i])
+		params[i] = "$" + strconv.Itoa(len(*args))
+	}
+	op := func(k keyColumn) string {
+		if k.desc != back {
+			return "<"
+		}
+		return ">"
+	}
+
+	// A row comparison can use a composite index, but only when every column sorts the same way.
+	uniform := true
+	for _, k := range keys[1:] {
+		if k.desc != keys[0].desc {
+			uniform = false
+		}
+	}
+	if uniform {
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = k.name
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(names, ", "), op(keys[0]), strings.Join(params, ", "))
+	}
+
+	// Mixed directions: (a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND id > $3) ...
+	ors := make([]string, len(keys))
+	for i, k := range keys {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, keys[j].name+" = "+params[j])
+		}
+		ands = append(ands, k.name+" "+op(k)+" "+params[i])
+		ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+	}
+	return "(" + strings.Join(ors, " OR ") + ")"
+}
+
+// orderBy returns the ORDER BY list for keys, reversed when back is true.
+func orderBy(keys []keyColumn, back bool) string {
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		dir := "ASC"
+		if k.desc != back {
+			dir = "DESC"
+		}
+		order[i] = k.name + " " + dir
+	}
+	return strings.Join(order, ", ")
+}
+
+// paginate runs the keyset query for req. scan reads one row; key returns the row's values
+// for ks.keys, in order.
+func paginate[T any](ctx context.Context, base *BaseRepository, ks keyset, req model.PageRequest,
+	scan func(*sql.Rows) (T, error), key func(T) []interface{}) (*model.Page[T], error) {
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = model.DefaultPageLimit
+	}
+
+	where := append([]string(nil), ks.where...)
+	args := append([]interface{}(nil), ks.args...)
+
+	var cur cursor
+	if req.Cursor != "" {
+		var values []interface{}
+		var err error
+		cur, values, err = decodeCursor(req.Cursor, ks)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, seekCondition(ks.keys, values, cur.Prev, &args))
+	}
+
+	// Reading back (prev) walks the ordering in reverse and flips the rows afterwards.
+	order := orderBy(ks.keys, cur.Prev)
+
+	query := "SELECT " + ks.columns + " FROM " + ks.from
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	// Fetch one extra row to learn whether another page follows.
+	query += " ORDER BY " + order + " LIMIT " + strconv.Itoa(limit+1)
+
+	items := []T{}
+	err := base.ScanRows(ctx, query, func(rows *sql.Rows) error {
+		for rows.Next() {
+			item, err := scan(rows)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return rows.Err()
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if cur.Prev {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	page := &model.Page[T]{Items: items, Limit: limit}
+	if len(items) > 0 {
+		first, last := items[0], items[len(items)-1]
+		// Going forward there is a previous page whenever we started from a cursor, and a
+		// next page if the extra row came back; going back it's the other way round.
+		hasPrev, hasNext := req.Cursor != "", more
+		if cur.Prev {
+			hasPrev, hasNext = more, true
+		}
+		sig := ks.signature()
+		if hasNext {
+			page.NextCursor = encodeCursor(cursor{Sort: sig, Values: formatKey(key(last))})
+		}
+		if hasPrev {
+			page.PrevCursor = encodeCursor(cursor{Sort: sig, Values: formatKey(key(first)), Prev: true})
+		}
+	}
+
+	if req.IncludeTotal {
+		countQuery := "SELECT COUNT(*) FROM " + ks.from
+		if len(ks.where) > 0 {
+			countQuery += " WHERE " + strings.Join(ks.where, " AND ")
+		}
+		var total int64
+		if err := base.ScanRow(ctx, countQuery, func(row *sql.Row) error { return row.Scan(&total) }, ks.args...); err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+	return page, nil
+}
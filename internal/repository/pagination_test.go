@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testKeyset = keyset{
+	keys: []keyColumn{
+		{name: "joined_at", kind: keyTime, desc: true},
+		{name: "name", kind: keyString},
+		{name: "score", kind: keyFloat},
+		{name: "id", kind: keyInt},
+	},
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	joined := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name   string
+		values []interface{}
+		prev   bool
+	}{
+		{"forward", []interface{}{joined, "Ann", 0.1, int64(42)}, false},
+		{"backward", []interface{}{joined, "Ann", 0.1, int64(42)}, true},
+		{"unicode and separators", []interface{}{joined, `Zoë, "Jr." / ~`, -1e-300, int64(-1)}, false},
+		{"empty string and large id", []interface{}{time.Time{}, "", 1.0 / 3, int64(1<<62 + 1)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := encodeCursor(cursor{Sort: testKeyset.signature(), Values: formatKey(tt.values), Prev: tt.prev})
+			c, values, err := decodeCursor(s, testKeyset)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q): %v", s, err)
+			}
+			if c.Prev != tt.prev {
+				t.Errorf("Prev = %v, want %v", c.Prev, tt.prev)
+			}
+			if !values[0].(time.Time).Equal(tt.values[0].(time.Time)) {
+				t.Errorf("time = %v, want %v", values[0], tt.values[0])
+			}
+			if !reflect.DeepEqual(values[1:], tt.values[1:]) {
+				t.Errorf("values = %#v, want %#v", values[1:], tt.values[1:])
+			}
+		})
+	}
+}
+
+func TestCursorIsURLSafe(t *testing.T) {
+	s := encodeCursor(cursor{Sort: testKeyset.signature(), Values: []string{"???", ">>>", "~~~", "1"}})
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '_') {
+			t.Fatalf("cursor %q contains %q", s, r)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	sig := testKeyset.signature()
+	valid := []string{"2024-03-01T12:30:00Z", "Ann", "0.5", "42"}
+	raw := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+
+	scoped := testKeyset
+	scoped.scope = "smith"
+	other := testKeyset
+	other.keys = append([]keyColumn{{name: "joined_at", kind: keyTime}}, testKeyset.keys[1:]...)
+
+	tests := []struct {
+		name   string
+		cursor string
+		ks     keyset
+	}{
+		{"not base64", "!!!", testKeyset},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"v":[]}`)), testKeyset},
+		{"not json", raw("not json"), testKeyset},
+		{"other sort", encodeCursor(cursor{Sort: other.signature(), Values: valid}), testKeyset},
+		{"other scope", encodeCursor(cursor{Sort: sig, Values: valid}), scoped},
+		{"too few values", encodeCursor(cursor{Sort: sig, Values: valid[:3]}), testKeyset},
+		{"too many values", encodeCursor(cursor{Sort: sig, Values: append(valid, "1")}), testKeyset},
+		{"bad time", encodeCursor(cursor{Sort: sig, Values: []string{"2024-03-01", "Ann", "0.5", "42"}}), testKeyset},
+		{"bad float", encodeCursor(cursor{Sort: sig, Values: []string{valid[0], "Ann", "half", "42"}}), testKeyset},
+		{"bad int", encodeCursor(cursor{Sort: sig, Values: []string{valid[0], "Ann", "0.5", "4.2"}}), testKeyset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCursor(tt.cursor, tt.ks); err != ErrInvalidCursor {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	if got, want := testKeyset.signature(), "-joined_at,name,score,id"; got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	a, b := testKeyset, testKeyset
+	a.scope, b.scope = "smith", "jones"
+	if a.signature() == b.signature() || a.signature() == testKeyset.signature() {
+		t.Errorf("scoped signatures collide: %q, %q", a.signature(), b.signature())
+	}
+}
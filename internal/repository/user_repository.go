@@ -6,82 +6,218 @@ import (
 	"time"
 
 	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/pkg/db"
 )
 
 // UserRepository provides CRUD access to users in Postgres. It uses BaseRepository to reduce boilerplate.
+// Deleted users stay in the table with deleted_at set; every read except the trash
+// methods skips them, so a deleted user can no longer log in. Every write also appends
+// to the audit trail in the same transaction; password hashes are never recorded.
 type UserRepository struct {
-	base *BaseRepository
+	base  *BaseRepository
+	live  *Repository[model.User] // users not in the trash
+	trash *Repository[model.User]
 }
 
-// NewUserRepository creates a new user repository with a DB handle.
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{base: NewBaseRepository(db)}
+// NewUserRepository creates a new user repository on the pools of router.
+func NewUserRepository(router *db.Router) *UserRepository {
+	base := NewBaseRepository(router)
+	users := NewRepository[model.User](base, "users")
+	return &UserRepository{
+		base:  base,
+		live:  users.Scope("deleted_at IS NULL"),
+		trash: users.Scope("deleted_at IS NOT NULL"),
+	}
 }
 
-// Create inserts a new user and returns the new ID.
+// Create inserts a new user and returns the new ID; u.ID and u.Version are set to the
+// values the database assigned.
 func (r *UserRepository) Create(ctx context.Context, u *model.User) (int64, error) {
-	now := time.Now().UTC()
-	var id int64
-	err := r.base.ScanRow(ctx,
-		`INSERT INTO users (name, email, created_at) VALUES ($1, $2, $3) RETURNING id`,
-		func(row *sql.Row) error {
-			return row.Scan(&id)
-		},
-		u.Name, u.Email, now,
-	)
-	return id, err
+	u.CreatedAt, u.DeletedAt = time.Now().UTC(), nil
+	err := r.base.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.live.Insert(ctx, u); err != nil {
+			return err
+		}
+		return r.audit(ctx, u.ID, auditCreate, nil, u)
+	})
+	return u.ID, err
 }
 
 // GetByID returns a single user by ID.
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
-	var u model.User
-	err := r.base.ScanRow(ctx,
-		`SELECT id, name, email, created_at FROM users WHERE id = $1`,
-		func(row *sql.Row) error {
-			return row.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt)
-		},
-		id,
-	)
-	if err != nil {
+	return r.live.Get(ctx, id)
+}
+
+// GetByEmail returns a user by email, including the password hash (for login).
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.live.GetBy(ctx, "email", email)
+}
+
+// UpdatePassword replaces the password hash of an existing user and returns the user's
+// new version, which changes like on any other write so a stale If-Match fails. It
+// returns 0 when the user does not exist.
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) (int64, error) {
+	var version int64
+	err := r.base.Transaction(ctx, func(ctx context.Context) error {
+		err := r.base.WriteRow(ctx,
+			`UPDATE users SET password_hash=$1, version=version+1 WHERE id=$2 AND deleted_at IS NULL RETURNING version`,
+			func(row *sql.Row) error {
+				return row.Scan(&version)
+			},
+			passwordHash, id,
+		)
 		if err == sql.ErrNoRows {
-			return nil, nil
+			version = 0
+			return nil
 		}
-		return nil, err
-	}
-	return &u, nil
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, r.base, auditUser, id, auditUpdate, map[string]model.FieldChange{
+			"password": {Old: redacted, New: redacted},
+		})
+	})
+	return version, err
 }
 
-// Update modifies name and email of an existing user.
+// Update modifies name, email and (when non-empty) role of an existing user. A non-zero
+// u.Version must match the stored version or ErrVersionMismatch is returned; on success
+// u.Version is set to the new version.
 func (r *UserRepository) Update(ctx context.Context, u *model.User) error {
-	return r.base.ExecUpdate(ctx,
-		`UPDATE users SET name=$1, email=$2 WHERE id=$3`,
-		u.Name, u.Email, u.ID,
-	)
-}
-
-// Delete removes a user by ID.
-func (r *UserRepository) Delete(ctx context.Context, id int64) error {
-	return r.base.ExecUpdate(ctx,
-		`DELETE FROM users WHERE id=$1`,
-		id,
-	)
-}
-
-// List returns all users (small dataset for this example).
-func (r *UserRepository) List(ctx context.Context) ([]*model.User, error) {
-	var users []*model.User
-	err := r.base.ScanRows(ctx,
-		`SELECT id, name, email, created_at FROM users ORDER BY id`,
-		func(rows *sql.Rows) error {
-			for rows.Next() {
-				var u model.User
-				if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt); err != nil {
-					return err
-				}
-				users = append(users, &u)
-			}
-			return rows.Err()
-		},
-	)
-	return users, err
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, u.ID)
+		if err != nil || before == nil {
+			return err
+		}
+		if u.Version != 0 && u.Version != before.Version {
+			return ErrVersionMismatch
+		}
+		after := *before
+		after.Name, after.Email = u.Name, u.Email
+		cols := []string{"name", "email"}
+		if u.Role != "" {
+			after.Role = u.Role
+			cols = append(cols, "role")
+		}
+		if _, err := r.live.Update(ctx, &after, cols...); err != nil {
+			return err
+		}
+		u.Version = after.Version
+		return r.audit(ctx, u.ID, auditUpdate, before, &after)
+	})
+}
+
+// Patch writes only the columns in which u differs from the stored user: name, email and
+// role. Version checking is as in Update; the password is left to UpdatePassword. When
+// nothing changed no row is written and u.Version is set to the stored version.
+func (r *UserRepository) Patch(ctx context.Context, u *model.User) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, u.ID)
+		if err != nil || before == nil {
+			return err
+		}
+		if u.Version != 0 && u.Version != before.Version {
+			return ErrVersionMismatch
+		}
+		after := *before
+		after.Name, after.Email, after.Role = u.Name, u.Email, u.Role
+		cols := r.live.Changed(before, &after, "name", "email", "role")
+		if len(cols) == 0 {
+			u.Version = before.Version
+			return nil
+		}
+		if _, err := r.live.Update(ctx, &after, cols...); err != nil {
+			return err
+		}
+		u.Version = after.Version
+		return r.audit(ctx, u.ID, auditUpdate, before, &after)
+	})
+}
+
+// Delete moves a user to the trash by setting deleted_at. A non-zero version must match
+// the stored version or ErrVersionMismatch is returned.
+func (r *UserRepository) Delete(ctx context.Context, id, version int64) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetForUpdate(ctx, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if version != 0 && version != before.Version {
+			return ErrVersionMismatch
+		}
+		now := time.Now().UTC()
+		after := *before
+		after.DeletedAt = &now
+		if _, err := r.live.Update(ctx, &after, "deleted_at"); err != nil {
+			return err
+		}
+		return r.audit(ctx, id, auditDelete, before, &after)
+	})
+}
+
+// GetDeleted returns a user from the trash, or nil, nil if id is not in the trash.
+func (r *UserRepository) GetDeleted(ctx context.Context, id int64) (*model.User, error) {
+	return r.trash.Get(ctx, id)
+}
+
+// Restore takes a user out of the trash.
+func (r *UserRepository) Restore(ctx context.Context, id int64) error {
+	return r.base.Transaction(ctx, func(ctx context.Context) error {
+		before, err := r.GetDeleted(ctx, id)
+		if err != nil || before == nil {
+			return err
+		}
+		after := *before
+		after.DeletedAt = nil
+		if _, err := r.trash.Update(ctx, &after, "deleted_at"); err != nil {
+			return err
+		}
+		return r.audit(ctx, id, auditRestore, before, &after)
+	})
+}
+
+// ListTrash returns one page of deleted users, most recently deleted first.
+func (r *UserRepository) ListTrash(ctx context.Context, page model.PageRequest) (*model.Page[*model.User], error) {
+	return paginate(ctx, r.base, keyset{
+		columns: r.trash.columnList(),
+		from:    "users",
+		where:   []string{"deleted_at IS NOT NULL"},
+		keys:    trashKeys,
+	}, page, r.trash.scan, func(u *model.User) []interface{} {
+		return []interface{}{*u.DeletedAt, u.ID}
+	})
+}
+
+// Purge permanently deletes up to limit users that were moved to the trash before cutoff
+// and returns how many it removed (see ChurchMemberRepository.Purge).
+func (r *UserRepository) Purge(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return purgeAudited(ctx, r.base, "users", auditUser, cutoff, limit)
+}
+
+// GetForUpdate returns a user by ID and locks the row until the surrounding transaction
+// ends. It returns nil, nil when the user does not exist.
+func (r *UserRepository) GetForUpdate(ctx context.Context, id int64) (*model.User, error) {
+	return r.live.GetForUpdate(ctx, id)
+}
+
+// audit records the fields that differ between before and after in the audit trail;
+// ctx should carry the transaction making the change.
+func (r *UserRepository) audit(ctx context.Context, id int64, action string, before, after *model.User) error {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, r.base, auditUser, id, action, changes)
+}
+
+// List returns one page of users ordered by id.
+func (r *UserRepository) List(ctx context.Context, page model.PageRequest) (*model.Page[*model.User], error) {
+	return paginate(ctx, r.base, keyset{
+		columns: r.live.columnList(),
+		from:    "users",
+		where:   []string{"deleted_at IS NULL"},
+		keys:    []keyColumn{{name: "id", kind: keyInt}},
+	}, page, r.live.scan, func(u *model.User) []interface{} {
+		return []interface{}{u.ID}
+	})
 }
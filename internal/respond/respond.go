@@ -0,0 +1,58 @@
+// Package respond writes every HTTP response in the model.ResponseModel envelope and maps
+// apperror kinds to status codes, so handlers and middleware never hand-roll error bodies.
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// JSON writes a success envelope carrying result with the given status.
+func JSON(w http.ResponseWriter, status int, result interface{}) {
+	Envelope(w, status, model.NewSuccessResponse(result))
+}
+
+// NoContent writes a 204 with no body.
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Error maps err to a status code and writes an error envelope. Untyped errors become
+// 500s whose cause is logged but not sent to the client.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	ErrorResult(w, r, err, nil)
+}
+
+// ErrorResult is Error with result set in the envelope, for errors that carry useful
+// state, such as the current version of a record after a failed precondition.
+func ErrorResult(w http.ResponseWriter, r *http.Request, err error, result interface{}) {
+	appErr := apperror.As(err)
+	status := apperror.HTTPStatus(appErr.Kind)
+
+	log := logger.FromContext(r.Context())
+	if status >= http.StatusInternalServerError {
+		log.Error("request failed", "error", err, "error_code", appErr.Code)
+	} else {
+		log.Info("request rejected", "error", err, "error_code", appErr.Code, "status", status)
+	}
+
+	if appErr.Kind == apperror.KindUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+	resp := model.NewErrorResponse(appErr.Message)
+	resp.ErrorCode = appErr.Code
+	resp.Errors = appErr.Fields
+	resp.Result = result
+	Envelope(w, status, resp)
+}
+
+// Envelope writes resp as JSON with the given status.
+func Envelope(w http.ResponseWriter, status int, resp *model.ResponseModel) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// background tracks long-running goroutines started by Run (purge jobs, cache refreshers, ...)
+// so shutdown can cancel them and wait for them to return before the DB pool is closed.
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a goroutine; fn must return promptly once ctx is cancelled.
+func (b *background) Go(fn func(ctx context.Context)) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn(b.ctx)
+	}()
+}
+
+// Stop cancels all jobs and waits for them to finish, or for ctx to expire.
+func (b *background) Stop(ctx context.Context) error {
+	b.cancel()
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/internal/service"
+)
+
+// trashPurger permanently deletes members and users that have been in the trash for
+// longer than retention. Running it on several instances at once is harmless.
+type trashPurger struct {
+	members   *service.ChurchMemberService
+	users     *service.UserService
+	retention time.Duration
+	interval  time.Duration
+}
+
+// run purges once at startup and then every interval until ctx is cancelled.
+func (p *trashPurger) run(ctx context.Context) {
+	every(ctx, p.interval, p.purge)
+}
+
+func (p *trashPurger) purge(ctx context.Context) {
+	cutoff := time.Now().UTC().Add(-p.retention)
+	if _, err := p.members.PurgeTrash(ctx, cutoff); err != nil && ctx.Err() == nil {
+		slog.Error("purging trashed members failed", "error", err)
+	}
+	if _, err := p.users.PurgeTrash(ctx, cutoff); err != nil && ctx.Err() == nil {
+		slog.Error("purging trashed users failed", "error", err)
+	}
+}
+
+// idempotencyPurger deletes expired Idempotency-Keys.
+type idempotencyPurger struct {
+	keys     *repository.IdempotencyRepository
+	interval time.Duration
+}
+
+// run purges once at startup and then every interval until ctx is cancelled.
+func (p *idempotencyPurger) run(ctx context.Context) {
+	every(ctx, p.interval, p.purge)
+}
+
+func (p *idempotencyPurger) purge(ctx context.Context) {
+	n, err := p.keys.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("purging expired idempotency keys failed", "error", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.Info("expired idempotency keys purged", "count", n)
+	}
+}
+
+// every calls fn once right away and then every interval until ctx is cancelled.
+func every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,54 +1,263 @@
 package server
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
 
+	"github.com/example/golang-project/internal/auth"
 	"github.com/example/golang-project/internal/handler"
+	"github.com/example/golang-project/internal/metrics"
 	"github.com/example/golang-project/internal/middleware"
 	"github.com/example/golang-project/internal/repository"
 	"github.com/example/golang-project/internal/service"
+	"github.com/example/golang-project/migrations"
+	"github.com/example/golang-project/pkg/db"
+	"github.com/example/golang-project/pkg/db/config"
+	"github.com/example/golang-project/pkg/db/migrate"
 )
 
+// Default http.Server timeouts, used when the corresponding config value is empty.
+const (
+	defaultAddr              = ":8080"
+	defaultReadTimeout       = 15 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultShutdownTimeout   = 20 * time.Second
+	defaultTokenIssuer       = "users-service"
+	defaultAccessTokenTTL    = 15 * time.Minute
+	defaultTrashRetention    = 30 * 24 * time.Hour
+	defaultPurgeInterval     = time.Hour
+	defaultIdempotencyTTL    = 24 * time.Hour
+	defaultReplicaInterval   = 5 * time.Second
+)
+
+// defaultPublicRoutes are reachable without a token when Auth.PublicRoutes is not configured.
+var defaultPublicRoutes = []string{"/auth/login", "/healthz", "/readyz", "/metrics", "/swagger/"}
+
 // Run wires dependencies (repo -> service -> handlers), sets up routes and starts the HTTP server.
-func Run(addr string, db *sql.DB) error {
+// It blocks until SIGINT/SIGTERM, then fails readiness, drains in-flight requests, stops
+// background work and closes dbConn and the replicas, in that order. Run owns dbConn
+// from this point on and closes it even when setup fails.
+func Run(conf *config.Config, dbConn *sql.DB) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Everything that can fail comes first, so an error leaves nothing running to clean
+	// up but dbConn.
+	migrator, err := migrate.New(dbConn, migrations.FS)
+	if err != nil {
+		dbConn.Close()
+		return err
+	}
+	healthHandler := handler.NewHealthHandler(dbConn, migrator)
+
+	issuer := conf.Auth.Issuer
+	if issuer == "" {
+		issuer = defaultTokenIssuer
+	}
+	tokens, err := auth.NewTokenManager(conf.Auth.JWTSecret, issuer, conf.Auth.AccessTokenTTL.Or(defaultAccessTokenTTL))
+	if err != nil {
+		dbConn.Close()
+		return err
+	}
+	publicRoutes := conf.Auth.PublicRoutes
+	if publicRoutes == nil {
+		publicRoutes = defaultPublicRoutes
+	}
+
+	// Reads outside transactions go to read replicas, if configured and healthy.
+	replicas, err := openReplicas(conf.Database.ReplicaConnectionStrings)
+	if err != nil {
+		dbConn.Close()
+		return err
+	}
+	router := db.NewRouter(dbConn, replicas...)
+
+	jobs := newBackground()
+	if len(replicas) > 0 {
+		interval := conf.Database.ReplicaCheckInterval.Or(defaultReplicaInterval)
+		jobs.Go(func(ctx context.Context) { every(ctx, interval, router.CheckReplicas) })
+		slog.Info("read replicas configured", "count", len(replicas))
+	}
+
+	// Services run multi-step writes in transactions that the repositories pick up from the context.
+	uow := db.NewUnitOfWork(dbConn)
+
 	// user repository and service
-	userRepo := repository.NewUserRepository(db)
-	userSvc := service.NewUserService(userRepo)
+	userRepo := repository.NewUserRepository(router)
+	userSvc := service.NewUserService(userRepo, uow)
 	userHandler := handler.NewUserHandler(userSvc)
+	authHandler := handler.NewAuthHandler(service.NewAuthService(userRepo, tokens))
 
 	// church member repository and service
-	churchRepo := repository.NewChurchMemberRepository(db)
-	churchSvc := service.NewChurchMemberService(churchRepo)
+	churchRepo := repository.NewChurchMemberRepository(router)
+	churchSvc := service.NewChurchMemberService(churchRepo, uow)
 	churchHandler := handler.NewChurchMemberHandler(churchSvc)
 
+	// audit trail (written by the repositories above, read here)
+	auditHandler := handler.NewAuditHandler(service.NewAuditService(repository.NewAuditRepository(router)))
+
+	metrics.RegisterDBStats(metrics.Default, dbConn)
+	metrics.RegisterTxRetries(metrics.Default)
+	metrics.RegisterReplicas(metrics.Default, router)
+
+	// Trashed members and users are purged once the retention period has passed;
+	// a negative Retention.Trash keeps them forever.
+	if conf.Retention.Trash >= 0 {
+		purger := &trashPurger{members: churchSvc, users: userSvc,
+			retention: conf.Retention.Trash.Or(defaultTrashRetention),
+			interval:  conf.Retention.PurgeInterval.Or(defaultPurgeInterval)}
+		jobs.Go(purger.run)
+	} else {
+		slog.Info("trash purge disabled")
+	}
+
+	// Responses stored for Idempotency-Keys are replayed until they expire.
+	idempotencyRepo := repository.NewIdempotencyRepository(router)
+	jobs.Go((&idempotencyPurger{keys: idempotencyRepo,
+		interval: conf.Retention.PurgeInterval.Or(defaultPurgeInterval)}).run)
+
 	r := mux.NewRouter()
+	r.Use(middleware.MetricsMiddleware)
+	middleware.CountUnmatched(r)
+	r.Use(middleware.AuthMiddleware(tokens, publicRoutes))
+	r.Use(middleware.ReadYourWritesMiddleware)
+	r.Use(middleware.IdempotencyMiddleware(idempotencyRepo, conf.Retention.IdempotencyKeys.Or(defaultIdempotencyTTL)))
+
+	// Auth routes
+	r.HandleFunc("/auth/login", authHandler.LoginHandler).Methods("POST")
+	r.HandleFunc("/auth/me", authHandler.MeHandler).Methods("GET")
 
 	// User routes
-	r.HandleFunc("/users", userHandler.CreateUserHandler).Methods("POST")
-	r.HandleFunc("/users", userHandler.ListUsersHandler).Methods("GET")
-	r.HandleFunc("/users/{id}", userHandler.GetUserHandler).Methods("GET")
-	r.HandleFunc("/users/{id}", userHandler.UpdateUserHandler).Methods("PUT")
-	r.HandleFunc("/users/{id}", userHandler.DeleteUserHandler).Methods("DELETE")
+	r.Handle("/users", requires(auth.PermUsersWrite, userHandler.CreateUserHandler)).Methods("POST")
+	r.Handle("/users", requires(auth.PermUsersRead, userHandler.ListUsersHandler)).Methods("GET")
+	r.Handle("/users/trash", requires(auth.PermUsersDelete, userHandler.ListTrashHandler)).Methods("GET")
+	r.Handle("/users/{id}", requires(auth.PermUsersRead, userHandler.GetUserHandler)).Methods("GET")
+	r.Handle("/users/{id}", requires(auth.PermUsersWrite, userHandler.UpdateUserHandler)).Methods("PUT")
+	r.Handle("/users/{id}", requires(auth.PermUsersWrite, userHandler.PatchUserHandler)).Methods("PATCH")
+	r.Handle("/users/{id}", requires(auth.PermUsersDelete, userHandler.DeleteUserHandler)).Methods("DELETE")
+	r.Handle("/users/{id}/restore", requires(auth.PermUsersDelete, userHandler.RestoreUserHandler)).Methods("POST")
 
 	// Church member routes
-	r.HandleFunc("/members", churchHandler.CreateMemberHandler).Methods("POST")
-	r.HandleFunc("/members", churchHandler.ListMembersHandler).Methods("GET")
-	r.HandleFunc("/members/joined", churchHandler.ListMembersByDateHandler).Methods("GET")
-	r.HandleFunc("/members/{id}", churchHandler.GetMemberHandler).Methods("GET")
-	r.HandleFunc("/members/{id}", churchHandler.UpdateMemberHandler).Methods("PUT")
-	r.HandleFunc("/members/{id}", churchHandler.DeleteMemberHandler).Methods("DELETE")
+	r.Handle("/members", requires(auth.PermMembersWrite, churchHandler.CreateMemberHandler)).Methods("POST")
+	r.Handle("/members", requires(auth.PermMembersRead, churchHandler.ListMembersHandler)).Methods("GET")
+	r.Handle("/members/export", requires(auth.PermMembersExport, churchHandler.ExportMembersHandler)).Methods("GET")
+	r.Handle("/members/import", requires(auth.PermMembersImport, churchHandler.ImportMembersHandler)).Methods("POST")
+	r.Handle("/members/joined", requires(auth.PermMembersRead, churchHandler.ListMembersByDateHandler)).Methods("GET")
+	r.Handle("/members/search", requires(auth.PermMembersRead, churchHandler.SearchMembersHandler)).Methods("GET")
+	r.Handle("/members/duplicates", requires(auth.PermMembersMerge, churchHandler.ListDuplicatesHandler)).Methods("GET")
+	r.Handle("/members/trash", requires(auth.PermMembersDelete, churchHandler.ListTrashHandler)).Methods("GET")
+	r.Handle("/members/{id}", requires(auth.PermMembersRead, churchHandler.GetMemberHandler)).Methods("GET")
+	r.Handle("/members/{id}", requires(auth.PermMembersWrite, churchHandler.UpdateMemberHandler)).Methods("PUT")
+	r.Handle("/members/{id}", requires(auth.PermMembersWrite, churchHandler.PatchMemberHandler)).Methods("PATCH")
+	r.Handle("/members/{id}", requires(auth.PermMembersDelete, churchHandler.DeleteMemberHandler)).Methods("DELETE")
+	r.Handle("/members/{id}/merge", requires(auth.PermMembersMerge, churchHandler.MergeMemberHandler)).Methods("POST")
+	r.Handle("/members/{id}/restore", requires(auth.PermMembersDelete, churchHandler.RestoreMemberHandler)).Methods("POST")
+	r.Handle("/members/{id}/history", requires(auth.PermMembersRead, auditHandler.MemberHistoryHandler)).Methods("GET")
+
+	// Audit routes
+	r.Handle("/audit", requires(auth.PermAuditRead, auditHandler.ListAuditHandler)).Methods("GET")
+
+	// Health probes
+	r.HandleFunc("/healthz", healthHandler.LivenessHandler).Methods("GET")
+	r.HandleFunc("/readyz", healthHandler.ReadinessHandler).Methods("GET")
+
+	// Prometheus scrape endpoint
+	r.Handle("/metrics", metrics.Default.Handler()).Methods("GET")
 
 	// swagger UI
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
-	// Apply middleware (similar to .NET's middleware pipeline)
-	handler := middleware.RecoveryMiddleware(middleware.LoggingMiddleware(r))
+	// Apply middleware (similar to .NET's middleware pipeline).
+	// Outermost first: request ID -> access log -> panic recovery -> router.
+	handler := middleware.RequestIDMiddleware(middleware.LoggingMiddleware(middleware.RecoveryMiddleware(r)))
+
+	addr := conf.Server.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       conf.Server.ReadTimeout.Or(defaultReadTimeout),
+		ReadHeaderTimeout: conf.Server.ReadHeaderTimeout.Or(defaultReadHeaderTimeout),
+		WriteTimeout:      conf.Server.WriteTimeout.Or(defaultWriteTimeout),
+		IdleTimeout:       conf.Server.IdleTimeout.Or(defaultIdleTimeout),
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting server on "+addr, "addr", addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		// Listener failed (e.g. port in use) before any shutdown was requested.
+		runErr = err
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, draining in-flight requests")
+	}
+	stop() // a second signal now terminates the process immediately
+
+	// Fail readiness first and keep serving for ShutdownDelay, so load balancers
+	// notice and stop routing new requests before the listener closes.
+	healthHandler.SetShuttingDown()
+	if delay := time.Duration(conf.Server.ShutdownDelay); delay > 0 && runErr == nil {
+		time.Sleep(delay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout.Or(defaultShutdownTimeout))
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http shutdown failed", "error", err)
+		runErr = errors.Join(runErr, err)
+	}
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		slog.Error("background shutdown failed", "error", err)
+		runErr = errors.Join(runErr, err)
+	}
+	if err := router.Close(); err != nil {
+		slog.Error("db close failed", "error", err)
+		runErr = errors.Join(runErr, err)
+	}
+
+	slog.Info("server stopped")
+	return runErr
+}
+
+// openReplicas opens a pool for each replica connection string. On error it closes
+// those already opened.
+func openReplicas(connStrs []string) ([]*sql.DB, error) {
+	var replicas []*sql.DB
+	for i, connStr := range connStrs {
+		replica, err := db.OpenDB(connStr)
+		if err != nil {
+			for _, r := range replicas {
+				r.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
 
-	log.Printf("starting server on %s", addr)
-	return http.ListenAndServe(addr, handler)
+// requires wraps h so it only runs for callers whose role grants perm (see auth.HasPermission).
+func requires(perm auth.Permission, h http.HandlerFunc) http.Handler {
+	return middleware.RequirePermission(perm)(h)
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/internal/validation"
+)
+
+// AuditService reads the audit trail written by the repositories.
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+// NewAuditService constructs a new AuditService.
+func NewAuditService(r *repository.AuditRepository) *AuditService {
+	return &AuditService{repo: r}
+}
+
+// MemberHistory returns one page of the changes made to a church member, newest first.
+// Deleted and purged members keep their history, so an unknown ID yields an empty page.
+func (s *AuditService) MemberHistory(ctx context.Context, memberID int64, page model.PageRequest) (*model.Page[*model.AuditEntry], error) {
+	if memberID <= 0 {
+		return nil, ErrInvalidMemberID
+	}
+	if err := validation.Struct(page); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.MemberHistory(ctx, memberID, page)
+	return list, pageError(err)
+}
+
+// ListEntries returns one page of audit entries matching q, newest first unless q.Sort says otherwise.
+func (s *AuditService) ListEntries(ctx context.Context, q model.ListQuery, page model.PageRequest) (*model.Page[*model.AuditEntry], error) {
+	if err := validation.Struct(page); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, q, page)
+	return list, pageError(err)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// AuthService authenticates users and issues access tokens.
+type AuthService struct {
+	users  *repository.UserRepository
+	tokens *auth.TokenManager
+}
+
+// NewAuthService constructs a new AuthService.
+func NewAuthService(users *repository.UserRepository, tokens *auth.TokenManager) *AuthService {
+	return &AuthService{users: users, tokens: tokens}
+}
+
+// Login verifies email/password and returns a signed access token.
+func (s *AuthService) Login(ctx context.Context, email, password string) (*model.TokenResponse, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	hash := ""
+	if u != nil {
+		hash = u.PasswordHash
+	}
+	// CheckPassword runs bcrypt even for unknown users to keep timing uniform.
+	if !auth.CheckPassword(hash, password) || u == nil {
+		logger.FromContext(ctx).Info("login failed")
+		return nil, ErrInvalidCredentials
+	}
+
+	token, expiresAt, err := s.tokens.Issue(auth.Identity{UserID: u.ID, Email: u.Email, Name: u.Name, Role: auth.Role(u.Role)})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("login succeeded", "user_id", u.ID)
+	return &model.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(expiresAt).Seconds()),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
@@ -2,154 +2,193 @@ package service
 
 import (
 	"context"
-	"errors"
-	"regexp"
-	"strings"
 	"time"
 
+	"github.com/example/golang-project/internal/metrics"
 	"github.com/example/golang-project/internal/model"
 	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/db"
+	"github.com/example/golang-project/pkg/jsonpatch"
+	"github.com/example/golang-project/pkg/logger"
 )
 
-// ChurchMemberService contains business logic for church members.
+// ChurchMemberService contains business logic for church members. Reads and writes that
+// must see the same data run in one transaction through uow.
 type ChurchMemberService struct {
 	repo *repository.ChurchMemberRepository
+	uow  db.UnitOfWork
 }
 
 // NewChurchMemberService constructs a new ChurchMemberService.
-func NewChurchMemberService(r *repository.ChurchMemberRepository) *ChurchMemberService {
-	return &ChurchMemberService{repo: r}
+func NewChurchMemberService(r *repository.ChurchMemberRepository, uow db.UnitOfWork) *ChurchMemberService {
+	return &ChurchMemberService{repo: r, uow: uow}
 }
 
 // CreateMember validates and creates a new church member, returning the created ID.
 func (s *ChurchMemberService) CreateMember(ctx context.Context, m *model.ChurchMember) (int64, error) {
-	// Validate input
-	if err := s.validateMember(m); err != nil {
+	if err := validation.Struct(m); err != nil {
 		return 0, err
 	}
 
-	// Check if email already exists
-	existing, err := s.repo.GetByEmail(ctx, m.Email)
-	if err != nil {
-		return 0, err
-	}
-	if existing != nil {
-		return 0, errors.New("email already exists")
-	}
-
 	// Set default joined_at to now if not provided
 	if m.JoinedAt.IsZero() {
 		m.JoinedAt = time.Now().UTC()
 	}
 
-	return s.repo.Create(ctx, m)
+	// A taken email is reported by the unique index, which unlike a lookup beforehand
+	// also catches a concurrent create.
+	id, err := s.repo.Create(ctx, m)
+	if err != nil {
+		return 0, emailTaken(err)
+	}
+	metrics.MembersCreated.Inc()
+	logger.FromContext(ctx).Info("member created", "member_id", id)
+	return id, nil
 }
 
-// GetMember returns a church member by ID.
+// GetMember returns a church member by ID, or ErrMemberNotFound.
 func (s *ChurchMemberService) GetMember(ctx context.Context, id int64) (*model.ChurchMember, error) {
 	if id <= 0 {
-		return nil, errors.New("invalid member id")
+		return nil, ErrInvalidMemberID
 	}
-	return s.repo.GetByID(ctx, id)
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMemberNotFound
+	}
+	return m, nil
 }
 
-// UpdateMember updates an existing church member's information.
+// UpdateMember updates an existing church member's information. A non-zero m.Version is
+// the version the caller last read; if the member has changed since, ErrMemberModified is
+// returned. On success m.Version holds the new version.
 func (s *ChurchMemberService) UpdateMember(ctx context.Context, m *model.ChurchMember) error {
 	if m.ID <= 0 {
-		return errors.New("invalid member id")
+		return ErrInvalidMemberID
 	}
 
-	// Validate input
-	if err := s.validateMember(m); err != nil {
+	if err := validation.Struct(m); err != nil {
 		return err
 	}
 
-	// Check if member exists
-	existing, err := s.repo.GetByID(ctx, m.ID)
-	if err != nil {
-		return err
-	}
-	if existing == nil {
-		return errors.New("member not found")
-	}
+	version := m.Version
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		m.Version = version // Update overwrites it; a retried attempt must check the caller's version again
 
-	// Check if new email is already taken by another member
-	if m.Email != existing.Email {
-		emailExists, err := s.repo.GetByEmail(ctx, m.Email)
+		// Check if member exists; the row stays locked until the update commits
+		existing, err := s.repo.GetForUpdate(ctx, m.ID)
 		if err != nil {
 			return err
 		}
-		if emailExists != nil {
-			return errors.New("email already exists")
+		if existing == nil {
+			return ErrMemberNotFound
 		}
+		return s.repo.Update(ctx, m)
+	})
+	if err != nil {
+		return versionError(emailTaken(err), ErrMemberModified)
 	}
-
-	return s.repo.Update(ctx, m)
+	metrics.MembersUpdated.Inc()
+	logger.FromContext(ctx).Info("member updated", "member_id", m.ID)
+	return nil
 }
 
-// DeleteMember removes a church member by ID.
-func (s *ChurchMemberService) DeleteMember(ctx context.Context, id int64) error {
+// PatchMember applies a JSON Merge Patch or JSON Patch to a church member and saves only
+// the fields it changed; unlike UpdateMember it may also change joined_at. The patched
+// member must pass the same rules as on update. version is checked as in UpdateMember.
+// It returns the member as saved.
+func (s *ChurchMemberService) PatchMember(ctx context.Context, id, version int64, p *jsonpatch.Patch) (*model.ChurchMember, error) {
 	if id <= 0 {
-		return errors.New("invalid member id")
+		return nil, ErrInvalidMemberID
 	}
-	return s.repo.Delete(ctx, id)
-}
-
-// ListMembers returns all church members.
-func (s *ChurchMemberService) ListMembers(ctx context.Context) ([]*model.ChurchMember, error) {
-	return s.repo.List(ctx)
-}
-
-// ListMembersByJoinedDate returns members joined within a date range.
-func (s *ChurchMemberService) ListMembersByJoinedDate(ctx context.Context, startDate, endDate time.Time) ([]*model.ChurchMember, error) {
-	if startDate.After(endDate) {
-		return nil, errors.New("start date must be before end date")
+	var patched model.ChurchMember
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		patched = model.ChurchMember{}
+		current, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrMemberNotFound
+		}
+		if version != 0 && version != current.Version {
+			return ErrMemberModified
+		}
+		if err := applyPatch(p, current, &patched, memberReadOnly); err != nil {
+			return err
+		}
+		if err := validation.Struct(&patched); err != nil {
+			return err
+		}
+		return s.repo.Patch(ctx, &patched)
+	})
+	if err != nil {
+		return nil, emailTaken(err)
 	}
-	return s.repo.ListByJoinedDateRange(ctx, startDate, endDate)
+	metrics.MembersUpdated.Inc()
+	logger.FromContext(ctx).Info("member patched", "member_id", id, "version", patched.Version)
+	return &patched, nil
 }
 
-// validateMember checks if the member data is valid.
-func (s *ChurchMemberService) validateMember(m *model.ChurchMember) error {
-	// Validate name
-	name := strings.TrimSpace(m.Name)
-	if name == "" {
-		return errors.New("name is required")
-	}
-	if len(name) < 2 || len(name) > 255 {
-		return errors.New("name must be between 2 and 255 characters")
-	}
-
-	// Validate email
-	email := strings.TrimSpace(m.Email)
-	if email == "" {
-		return errors.New("email is required")
-	}
-	if !isValidEmail(email) {
-		return errors.New("invalid email format")
-	}
-
-	// Validate phone (optional, but if provided must be reasonable)
-	if m.Phone != "" && len(m.Phone) > 20 {
-		return errors.New("phone must not exceed 20 characters")
+// DeleteMember moves a church member to the trash; see RestoreMember and PurgeTrash.
+// A non-zero version must match the member's current version, as in UpdateMember.
+func (s *ChurchMemberService) DeleteMember(ctx context.Context, id, version int64) error {
+	if id <= 0 {
+		return ErrInvalidMemberID
 	}
-
-	// Validate address (optional)
-	if len(m.Address) > 500 {
-		return errors.New("address must not exceed 500 characters")
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrMemberNotFound
+		}
+		return s.repo.Delete(ctx, id, version)
+	})
+	if err != nil {
+		return versionError(err, ErrMemberModified)
 	}
+	metrics.MembersDeleted.Inc()
+	logger.FromContext(ctx).Info("member deleted", "member_id", id)
+	return nil
+}
 
-	// Validate biography (optional)
-	if len(m.Biography) > 5000 {
-		return errors.New("biography must not exceed 5000 characters")
+// ListMembers returns one page of church members matching q, newest first unless q.Sort says otherwise.
+func (s *ChurchMemberService) ListMembers(ctx context.Context, q model.ListQuery, page model.PageRequest) (*model.Page[*model.ChurchMember], error) {
+	if err := validation.Struct(page); err != nil {
+		return nil, err
 	}
+	list, err := s.repo.List(ctx, q, page)
+	return list, pageError(err)
+}
 
-	return nil
+// ListMembersByJoinedDate returns one page of members joined within a date range.
+// It is ListMembers with joined_at bounds added to q.
+func (s *ChurchMemberService) ListMembersByJoinedDate(ctx context.Context, startDate, endDate time.Time, q model.ListQuery, page model.PageRequest) (*model.Page[*model.ChurchMember], error) {
+	fields := validation.Fields(model.DateRange{Start: startDate, End: endDate})
+	fields = append(fields, validation.Fields(page)...)
+	if err := validation.Error(fields); err != nil {
+		return nil, err
+	}
+	q.Filters = append([]model.Filter{
+		{Field: "joined_at", Op: "gte", Value: startDate.Format(time.RFC3339Nano)},
+		{Field: "joined_at", Op: "lte", Value: endDate.Format(time.RFC3339Nano)},
+	}, q.Filters...)
+	list, err := s.repo.List(ctx, q, page)
+	return list, pageError(err)
 }
 
-// isValidEmail checks if an email format is valid using regex.
-func isValidEmail(email string) bool {
-	// Simple email regex validation
-	pattern := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
-	re := regexp.MustCompile(pattern)
-	return re.MatchString(email)
+// SearchMembers runs a full-text search over members and returns one page of hits, best first.
+func (s *ChurchMemberService) SearchMembers(ctx context.Context, search model.MemberSearch, page model.PageRequest) (*model.Page[*model.MemberSearchHit], error) {
+	fields := validation.Fields(search)
+	fields = append(fields, validation.Fields(page)...)
+	if err := validation.Error(fields); err != nil {
+		return nil, err
+	}
+	hits, err := s.repo.Search(ctx, search.Query, page)
+	return hits, pageError(err)
 }
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/metrics"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// Duplicate detection defaults.
+const (
+	DefaultDuplicateMinScore = 0.6
+	DefaultDuplicateLimit    = 50
+
+	// maxBlockSize skips blocking keys shared by so many members (a very common first
+	// name, say) that comparing them all would be quadratic and mostly noise.
+	maxBlockSize = 200
+)
+
+// Score weights; they sum to 1.
+const (
+	weightName    = 0.5
+	weightPhone   = 0.25
+	weightAddress = 0.15
+	weightEmail   = 0.10
+)
+
+// DuplicateQuery tunes FindDuplicates.
+type DuplicateQuery struct {
+	MinScore float64 `json:"min_score" validate:"min=0,max=1"` // 0 means DefaultDuplicateMinScore
+	Limit    int     `json:"limit" validate:"min=1,max=500"`
+}
+
+// memberFingerprint holds the normalized fields compared when scoring a pair.
+type memberFingerprint struct {
+	member     *model.ChurchMember
+	name       string
+	nameGrams  map[string]bool
+	phone      string
+	address    string
+	addrGrams  map[string]bool
+	emailLocal string
+}
+
+// FindDuplicates scores pairs of members that share a name token, phone number or
+// address and returns those scoring at least q.MinScore, best first.
+func (s *ChurchMemberService) FindDuplicates(ctx context.Context, q DuplicateQuery) ([]*model.DuplicateCandidate, error) {
+	if err := validation.Struct(q); err != nil {
+		return nil, err
+	}
+	if q.MinScore <= 0 {
+		q.MinScore = DefaultDuplicateMinScore
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultDuplicateLimit
+	}
+
+	members, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := findDuplicates(members, q.MinScore)
+	if len(candidates) > q.Limit {
+		candidates = candidates[:q.Limit]
+	}
+	logger.FromContext(ctx).Info("duplicate scan finished", "members", len(members), "candidates", len(candidates))
+	return candidates, nil
+}
+
+// findDuplicates scores the pairs of members that share a blocking key and returns those
+// scoring at least minScore, best first.
+func findDuplicates(members []*model.ChurchMember, minScore float64) []*model.DuplicateCandidate {
+	prints := make([]memberFingerprint, len(members))
+	blocks := map[string][]int{}
+	for i, m := range members {
+		prints[i] = fingerprint(m)
+		for _, key := range blockingKeys(prints[i]) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	// Only members sharing a blocking key are compared; each pair is scored once.
+	seen := map[[2]int]bool{}
+	candidates := []*model.DuplicateCandidate{}
+	for _, block := range blocks {
+		if len(block) < 2 || len(block) > maxBlockSize {
+			continue
+		}
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				pair := [2]int{block[x], block[y]}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+				a, b := prints[pair[0]], prints[pair[1]]
+				score, reasons := scorePair(a, b)
+				if score < minScore {
+					continue
+				}
+				candidates = append(candidates, &model.DuplicateCandidate{
+					Member: *a.member, Duplicate: *b.member, Score: score, Reasons: reasons,
+				})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Member.ID < candidates[j].Member.ID
+	})
+	return candidates
+}
+
+// MergeMembers folds req.DuplicateID into survivorID in one transaction: fields are
+// combined per req.Prefer, the duplicate moves to the trash and its ID redirects to the survivor.
+// It returns the merged survivor.
+func (s *ChurchMemberService) MergeMembers(ctx context.Context, survivorID int64, req model.MergeRequest) (*model.ChurchMember, error) {
+	if survivorID <= 0 {
+		return nil, ErrInvalidMemberID
+	}
+	fields := validation.Fields(req)
+	for field, source := range req.Prefer {
+		if !mergeableField(field) {
+			fields = append(fields, apperror.FieldError{Field: "prefer." + field, Code: "unknown_field",
+				Message: "prefer." + field + " is not a mergeable field; use name, email, phone, address or biography"})
+		} else if source != "survivor" && source != "duplicate" {
+			fields = append(fields, apperror.FieldError{Field: "prefer." + field, Code: "oneof",
+				Message: "prefer." + field + " must be survivor or duplicate"})
+		}
+	}
+	if err := validation.Error(fields); err != nil {
+		return nil, err
+	}
+	if req.DuplicateID == survivorID {
+		return nil, ErrMergeSelf
+	}
+
+	var merged *model.ChurchMember
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		// Lock in id order so two merges of the same pair can't deadlock.
+		first, second := survivorID, req.DuplicateID
+		if second < first {
+			first, second = second, first
+		}
+		locked := map[int64]*model.ChurchMember{}
+		for _, id := range []int64{first, second} {
+			m, err := s.repo.GetForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			locked[id] = m
+		}
+		survivor, duplicate := locked[survivorID], locked[req.DuplicateID]
+		if survivor == nil {
+			return ErrMemberNotFound
+		}
+		if duplicate == nil {
+			return ErrDuplicateAbsent
+		}
+
+		merged = combineMembers(survivor, duplicate, req.Prefer)
+		if err := validation.Struct(merged); err != nil {
+			return err
+		}
+
+		// Redirects pointing at the duplicate move to the survivor, since purging the duplicate
+		// later cascades to them; the delete must come before the update in case the survivor
+		// takes the duplicate's email.
+		if err := s.repo.AddRedirect(ctx, duplicate.ID, survivor.ID); err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, duplicate.ID, duplicate.Version); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, merged); err != nil {
+			return err
+		}
+		if !merged.JoinedAt.Equal(survivor.JoinedAt) {
+			if err := s.repo.UpdateJoinedAt(ctx, merged.ID, merged.JoinedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, emailTaken(err)
+	}
+
+	metrics.MembersMerged.Inc()
+	logger.FromContext(ctx).Info("members merged", "member_id", survivorID, "duplicate_id", req.DuplicateID)
+	return s.GetMember(ctx, survivorID)
+}
+
+// MergedInto returns the member that id was merged into, or 0 if it never was.
+func (s *ChurchMemberService) MergedInto(ctx context.Context, id int64) (int64, error) {
+	if id <= 0 {
+		return 0, nil
+	}
+	return s.repo.RedirectFor(ctx, id)
+}
+
+// combineMembers returns the survivor with fields taken from the duplicate where prefer
+// says so, or where the survivor's value is empty.
+func combineMembers(survivor, duplicate *model.ChurchMember, prefer map[string]string) *model.ChurchMember {
+	out := *survivor
+	pick := func(field string, dst *string, fromDuplicate string) {
+		switch prefer[field] {
+		case "duplicate":
+			*dst = fromDuplicate
+		case "survivor":
+		default:
+			if strings.TrimSpace(*dst) == "" {
+				*dst = fromDuplicate
+			}
+		}
+	}
+	pick("name", &out.Name, duplicate.Name)
+	pick("email", &out.Email, duplicate.Email)
+	pick("phone", &out.Phone, duplicate.Phone)
+	pick("address", &out.Address, duplicate.Address)
+	pick("biography", &out.Biography, duplicate.Biography)
+	if duplicate.JoinedAt.Before(out.JoinedAt) {
+		out.JoinedAt = duplicate.JoinedAt
+	}
+	return &out
+}
+
+func mergeableField(field string) bool {
+	switch field {
+	case "name", "email", "phone", "address", "biography":
+		return true
+	}
+	return false
+}
+
+// fingerprint normalizes the fields used for matching.
+func fingerprint(m *model.ChurchMember) memberFingerprint {
+	f := memberFingerprint{
+		member:  m,
+		name:    normalizeName(m.Name),
+		phone:   normalizePhone(m.Phone),
+		address: normalizeAddress(m.Address),
+	}
+	f.nameGrams = trigrams(f.name)
+	f.addrGrams = trigrams(f.address)
+	if at := strings.LastIndex(m.Email, "@"); at > 0 {
+		f.emailLocal = strings.ToLower(strings.NewReplacer(".", "", "_", "", "-", "").Replace(m.Email[:at]))
+	}
+	return f
+}
+
+// blockingKeys are the cheap keys two members must share to be compared at all.
+func blockingKeys(f memberFingerprint) []string {
+	var keys []string
+	for _, tok := range strings.Fields(f.name) {
+		if len(tok) >= 3 {
+			keys = append(keys, "n:"+tok)
+		}
+	}
+	if f.phone != "" {
+		keys = append(keys, "p:"+f.phone)
+	}
+	if f.address != "" {
+		keys = append(keys, "a:"+f.address)
+	}
+	if f.emailLocal != "" {
+		keys = append(keys, "e:"+f.emailLocal)
+	}
+	return keys
+}
+
+// scorePair combines name, phone, address and email-local-part similarity into 0..1.
+func scorePair(a, b memberFingerprint) (float64, []string) {
+	var score float64
+	reasons := []string{}
+
+	if sim := jaccard(a.nameGrams, b.nameGrams); sim > 0 {
+		score += weightName * sim
+		if sim == 1 {
+			reasons = append(reasons, "same name")
+		} else if sim >= 0.5 {
+			reasons = append(reasons, "similar name")
+		}
+	}
+	if a.phone != "" && a.phone == b.phone {
+		score += weightPhone
+		reasons = append(reasons, "same phone")
+	}
+	if a.address != "" && b.address != "" {
+		sim := jaccard(a.addrGrams, b.addrGrams)
+		score += weightAddress * sim
+		if sim == 1 {
+			reasons = append(reasons, "same address")
+		} else if sim >= 0.6 {
+			reasons = append(reasons, "similar address")
+		}
+	}
+	if a.emailLocal != "" && a.emailLocal == b.emailLocal {
+		score += weightEmail
+		reasons = append(reasons, "same email name")
+	}
+	return math.Round(score*1000) / 1000, reasons
+}
+
+// normalizeName lowercases, folds accents, drops punctuation and sorts the words, so
+// "Smith, John" and "john smith" compare equal, as do "José" and "Jose".
+func normalizeName(s string) string {
+	words := strings.FieldsFunc(foldAccents(strings.ToLower(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// normalizePhone keeps the last 9 digits, which ignores country codes and formatting.
+func normalizePhone(s string) string {
+	var digits []rune
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 7 {
+		return ""
+	}
+	if len(digits) > 9 {
+		digits = digits[len(digits)-9:]
+	}
+	return string(digits)
+}
+
+// addressAbbreviations expands common street abbreviations before comparing.
+var addressAbbreviations = map[string]string{
+	"st": "street", "rd": "road", "ave": "avenue", "av": "avenue", "blvd": "boulevard",
+	"dr": "drive", "ln": "lane", "ct": "court", "apt": "apartment", "no": "number",
+}
+
+// normalizeAddress lowercases, folds accents, drops punctuation and expands
+// addressAbbreviations.
+func normalizeAddress(s string) string {
+	words := strings.FieldsFunc(foldAccents(strings.ToLower(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		if full, ok := addressAbbreviations[w]; ok {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// accentFolder maps lowercase accented Latin letters to their base letters.
+var accentFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ă", "a", "ą", "a",
+	"ç", "c", "ć", "c", "č", "c", "ď", "d", "đ", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ė", "e", "ę", "e", "ě", "e",
+	"ğ", "g", "ì", "i", "í", "i", "î", "i", "ï", "i", "ī", "i", "į", "i", "ı", "i",
+	"ł", "l", "ľ", "l", "ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ō", "o", "ő", "o",
+	"ŕ", "r", "ř", "r", "ś", "s", "š", "s", "ş", "s", "ș", "s", "ť", "t", "ţ", "t", "ț", "t",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u", "ų", "u",
+	"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z",
+	"ß", "ss", "æ", "ae", "œ", "oe", "þ", "th", "ð", "d",
+)
+
+// foldAccents strips diacritics from lowercase Latin letters; other scripts are kept.
+func foldAccents(s string) string { return accentFolder.Replace(s) }
+
+// trigrams returns the set of 3-rune windows of each padded word, as pg_trgm does.
+func trigrams(s string) map[string]bool {
+	grams := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			grams[string(r[i:i+3])] = true
+		}
+	}
+	return grams
+}
+
+// jaccard is |a ∩ b| / |a ∪ b|.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for g := range a {
+		if b[g] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
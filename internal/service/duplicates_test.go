@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/example/golang-project/internal/model"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"John Smith", "john smith"},
+		{"SMITH, John", "john smith"},
+		{"  john \t  smith\n", "john smith"},
+		{"José Álvarez", "alvarez jose"},
+		{"José Alvarez", "alvarez jose"}, // decomposed accent
+		{"Zoë O'Brien-Ødegård", "brien o odegard zoe"},
+		{"Groß", "gross"},
+		{"Nguyễn", "nguyễn"}, // letters outside the fold table are kept
+		{"Иван Петров", "иван петров"},
+		{"J. R. R. Tolkien", "j r r tolkien"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeName(tt.in); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"+44 (0)20 7946 0958", "079460958"},
+		{"020 7946 0958", "079460958"},
+		{"555-0100", "5550100"}, // 7 digits are enough
+		{"555-010", ""},
+		{"555.123.4567", "551234567"},
+		{"+1 555 123 4567", "551234567"},
+		{"call me", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizePhone(tt.in); got != tt.want {
+			t.Errorf("normalizePhone(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"12 Main St., Apt 4", "12 main street apartment 4"},
+		{"12 MAIN STREET apartment 4", "12 main street apartment 4"},
+		{"  5  Rue   de la Paix ", "5 rue de la paix"},
+		{"Königstraße 3", "konigstrasse 3"},
+		{"1 Elm Ave / Blvd", "1 elm avenue boulevard"},
+	}
+	for _, tt := range tests {
+		if got := normalizeAddress(tt.in); got != tt.want {
+			t.Errorf("normalizeAddress(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprintEmailLocal(t *testing.T) {
+	tests := []struct{ email, want string }{
+		{"John.Smith@example.com", "johnsmith"},
+		{"john_smith@other.org", "johnsmith"},
+		{"j-smith-1@example.com", "jsmith1"},
+		{"a@b@example.com", "a@b"}, // the last @ separates the domain
+		{"@example.com", ""},
+		{"not-an-email", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := fingerprint(&model.ChurchMember{Email: tt.email}).emailLocal; got != tt.want {
+			t.Errorf("email local part of %q = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"john", "john", 1},
+		{"john", "", 0},
+		{"", "", 0},
+		{"abc", "xyz", 0},
+		{"ab", "abc", 2.0 / 5}, // {"  a"," ab","ab "} vs {"  a"," ab","abc","bc "}
+	}
+	for _, tt := range tests {
+		if got := jaccard(trigrams(tt.a), trigrams(tt.b)); got != tt.want {
+			t.Errorf("jaccard(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScorePair(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        model.ChurchMember
+		wantScore   float64
+		wantReasons []string
+	}{
+		{"nothing shared",
+			model.ChurchMember{Name: "Ann Lee"}, model.ChurchMember{Name: "Bob Ray"},
+			0, []string{}},
+		{"same name after normalisation",
+			model.ChurchMember{Name: "José Smith"}, model.ChurchMember{Name: "SMITH, jose"},
+			0.5, []string{"same name"}},
+		{"everything equal",
+			model.ChurchMember{Name: "John Smith", Phone: "+1 555 123 4567", Address: "12 Main St", Email: "john.smith@a.com"},
+			model.ChurchMember{Name: "john smith", Phone: "555-123-4567", Address: "12 main street", Email: "johnsmith@b.org"},
+			1, []string{"same name", "same phone", "same address", "same email name"}},
+		{"phone and email only",
+			model.ChurchMember{Name: "Ann Lee", Phone: "0201234567", Email: "x@a.com"},
+			model.ChurchMember{Name: "Bob Ray", Phone: "020 123 4567", Email: "x@b.com"},
+			0.35, []string{"same phone", "same email name"}},
+		{"empty phones never match",
+			model.ChurchMember{Name: "Ann Lee", Phone: "n/a"}, model.ChurchMember{Name: "Bob Ray", Phone: ""},
+			0, []string{}},
+		{"one address missing",
+			model.ChurchMember{Name: "Ann Lee", Address: "12 Main St"}, model.ChurchMember{Name: "Bob Ray"},
+			0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reasons := scorePair(fingerprint(&tt.a), fingerprint(&tt.b))
+			if score != tt.wantScore || !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("scorePair = %v %q, want %v %q", score, reasons, tt.wantScore, tt.wantReasons)
+			}
+			if again, _ := scorePair(fingerprint(&tt.b), fingerprint(&tt.a)); again != score {
+				t.Errorf("scorePair is not symmetric: %v then %v", score, again)
+			}
+		})
+	}
+}
+
+func TestFindDuplicatesThreshold(t *testing.T) {
+	// Same name and phone: 0.5 + 0.25.
+	members := []*model.ChurchMember{
+		{ID: 1, Name: "John Smith", Phone: "555 123 4567"},
+		{ID: 2, Name: "Smith John", Phone: "(555) 123-4567"},
+	}
+	tests := []struct {
+		minScore float64
+		want     int
+	}{
+		{0.5, 1},
+		{0.75, 1}, // the threshold is inclusive
+		{0.751, 0},
+		{1, 0},
+	}
+	for _, tt := range tests {
+		got := findDuplicates(members, tt.minScore)
+		if len(got) != tt.want {
+			t.Errorf("minScore %v: %d candidates, want %d", tt.minScore, len(got), tt.want)
+			continue
+		}
+		if tt.want == 1 && (got[0].Score != 0.75 || got[0].Member.ID != 1 || got[0].Duplicate.ID != 2) {
+			t.Errorf("minScore %v: got %+v", tt.minScore, got[0])
+		}
+	}
+}
+
+func TestFindDuplicatesOrder(t *testing.T) {
+	members := []*model.ChurchMember{
+		{ID: 1, Name: "Maria Garcia"},
+		{ID: 2, Name: "Maria Garcia", Phone: "5550001111"},
+		{ID: 3, Name: "Peter Parker", Phone: "5550002222"},
+		{ID: 4, Name: "Peter Parker", Phone: "5550002222"},
+	}
+	got := findDuplicates(members, 0.5)
+	var pairs []string
+	for _, c := range got {
+		pairs = append(pairs, fmt.Sprintf("%d-%d:%v", c.Member.ID, c.Duplicate.ID, c.Score))
+	}
+	want := []string{"3-4:0.75", "1-2:0.5"}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("candidates = %v, want %v", pairs, want)
+	}
+}
+
+func TestFindDuplicatesBlocking(t *testing.T) {
+	t.Run("pair without a shared key is never compared", func(t *testing.T) {
+		// Name tokens shorter than 3 letters are not blocking keys, so these two never
+		// meet even though their names would score 0.5.
+		a := &model.ChurchMember{ID: 1, Name: "Jo Li"}
+		b := &model.ChurchMember{ID: 2, Name: "Li Jo"}
+		if score, _ := scorePair(fingerprint(a), fingerprint(b)); score != 0.5 {
+			t.Fatalf("scorePair = %v, want 0.5", score)
+		}
+		if got := findDuplicates([]*model.ChurchMember{a, b}, 0.5); len(got) != 0 {
+			t.Errorf("got %d candidates, want none", len(got))
+		}
+	})
+
+	t.Run("oversized block is skipped", func(t *testing.T) {
+		// Every member shares "maria"; only the pair that also shares a surname is found.
+		var members []*model.ChurchMember
+		for i := 0; i <= maxBlockSize; i++ {
+			members = append(members, &model.ChurchMember{ID: int64(i + 1), Name: fmt.Sprintf("Maria Q%04d", i)})
+		}
+		members = append(members,
+			&model.ChurchMember{ID: 1001, Name: "Maria Fernandez"},
+			&model.ChurchMember{ID: 1002, Name: "Maria Fernandez"})
+		got := findDuplicates(members, 0.4)
+		if len(got) != 1 || got[0].Member.ID != 1001 || got[0].Duplicate.ID != 1002 {
+			t.Errorf("got %d candidates (%+v), want only 1001-1002", len(got), got)
+		}
+	})
+
+	t.Run("each pair is reported once", func(t *testing.T) {
+		// These share name tokens, phone, address and email: four blocks, one pair.
+		a := &model.ChurchMember{ID: 1, Name: "Anna Berg", Phone: "5551234567", Address: "1 Elm St", Email: "anna@a.com"}
+		b := &model.ChurchMember{ID: 2, Name: "Anna Berg", Phone: "5551234567", Address: "1 Elm St", Email: "anna@b.com"}
+		if got := findDuplicates([]*model.ChurchMember{a, b}, 0); len(got) != 1 {
+			t.Errorf("got %d candidates, want 1", len(got))
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/repository"
+)
+
+// Domain errors returned by the services. Handlers pass them to respond.Error, which
+// picks the HTTP status from the kind; compare with errors.Is.
+var (
+	ErrInvalidMemberID = apperror.Validation(apperror.CodeInvalidID, "invalid member id")
+	ErrMemberNotFound  = apperror.NotFound("MEMBER_NOT_FOUND", "member not found")
+	ErrEmailTaken      = &apperror.Error{Kind: apperror.KindConflict, Code: "EMAIL_TAKEN", Message: "email already exists",
+		Fields: []apperror.FieldError{{Field: "email", Code: "unique", Message: "email already exists"}}}
+	ErrMergeSelf       = apperror.Validation("MERGE_SELF", "a member cannot be merged into itself")
+	ErrDuplicateAbsent = apperror.NotFound("DUPLICATE_NOT_FOUND", "duplicate member not found")
+	ErrMemberModified  = apperror.PreconditionFailed("MEMBER_MODIFIED", "member was changed by someone else; reload it and try again")
+
+	ErrInvalidUserID    = apperror.Validation(apperror.CodeInvalidID, "invalid user id")
+	ErrUserNotFound     = apperror.NotFound("USER_NOT_FOUND", "user not found")
+	ErrPasswordTooShort = apperror.Validation("PASSWORD_TOO_SHORT", "password must be at least 8 characters")
+	ErrUserModified     = apperror.PreconditionFailed("USER_MODIFIED", "user was changed by someone else; reload it and try again")
+
+	// ErrPatchTestFailed means a JSON Patch test operation did not match the current record.
+	ErrPatchTestFailed = apperror.Conflict("PATCH_TEST_FAILED", "patch test operation failed")
+
+	// ErrInvalidCredentials is returned for an unknown email or a wrong password;
+	// the two cases are deliberately indistinguishable to the caller.
+	ErrInvalidCredentials = apperror.Unauthorized("INVALID_CREDENTIALS", "invalid email or password")
+)
+
+// ErrInvalidCursor is returned for a page cursor that is malformed or belongs to another list.
+var ErrInvalidCursor = apperror.InvalidFields([]apperror.FieldError{
+	{Field: "cursor", Code: "cursor", Message: "cursor is invalid; pass next_cursor or prev_cursor back unchanged"},
+})
+
+// versionError translates a repository version mismatch into modified, the domain error
+// of the entity being written.
+func versionError(err, modified error) error {
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		return modified
+	}
+	return err
+}
+
+// emailTaken translates a unique violation on an email column, which the database
+// reports when another live row has the address, into ErrEmailTaken.
+func emailTaken(err error) error {
+	var ce *repository.ConstraintError
+	if errors.As(err, &ce) && errors.Is(ce, repository.ErrUniqueViolation) && ce.Column == "email" {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// pageError translates repository pagination errors into domain errors.
+func pageError(err error) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return ErrInvalidCursor
+	}
+	return err
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/logger"
+	"github.com/example/golang-project/pkg/xlsx"
+)
+
+// Export formats accepted in model.ExportOptions.Format.
+const (
+	ExportCSV   = "csv"
+	ExportXLSX  = "xlsx"
+	ExportVCard = "vcf"
+)
+
+// exportFields are the member fields an export can include, by JSON name.
+var exportFields = []string{"id", "name", "email", "phone", "address", "biography", "joined_at", "created_at", "updated_at"}
+
+// defaultExportColumns are exported when no columns are requested. Biography is left out
+// because it rarely fits in a spreadsheet cell.
+var defaultExportColumns = []string{"id", "name", "email", "phone", "address", "joined_at"}
+
+// memberExporter writes members in one export format.
+type memberExporter interface {
+	write(m *model.ChurchMember) error
+	close() error
+}
+
+// ExportMembers writes every member matching q to w in opts.Format, one row (or vCard)
+// per member, reading members from the database as they are written. Options and
+// filters are checked before anything is written, so a validation error leaves w
+// untouched; a database error may arrive after part of the export has been written.
+func (s *ChurchMemberService) ExportMembers(ctx context.Context, q model.ListQuery, opts model.ExportOptions, w io.Writer) error {
+	fields := validation.Fields(opts)
+	for _, c := range opts.Columns {
+		if !contains(exportFields, c) {
+			fields = append(fields, apperror.FieldError{
+				Field: "columns", Code: "unknown_field", Message: strconv.Quote(c) + " cannot be exported; allowed fields: " + strings.Join(exportFields, ", "),
+			})
+		}
+	}
+	if err := validation.Error(fields); err != nil {
+		return err
+	}
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = defaultExportColumns
+	}
+
+	// The exporter is created on the first member, so that filter errors reported by
+	// Each come back before any output.
+	var out memberExporter
+	rows := 0
+	start := func() (err error) {
+		out, err = newMemberExporter(w, opts.Format, columns)
+		return err
+	}
+	err := s.repo.Each(ctx, q, func(m *model.ChurchMember) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		return out.write(m)
+	})
+	if err == nil && out == nil {
+		err = start()
+	}
+	if err != nil {
+		return err
+	}
+	if err := out.close(); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("members exported", "format", opts.Format, "rows", rows)
+	return nil
+}
+
+func newMemberExporter(w io.Writer, format string, columns []string) (memberExporter, error) {
+	switch format {
+	case ExportXLSX:
+		xw, err := xlsx.NewWriter(w, "Members")
+		if err != nil {
+			return nil, err
+		}
+		e := &xlsxExporter{w: xw, columns: columns}
+		return e, xw.WriteRow(columns)
+	case ExportVCard:
+		return &vcardExporter{w: w, columns: columns}, nil
+	default:
+		e := &csvExporter{w: csv.NewWriter(w), columns: columns}
+		return e, e.w.Write(columns)
+	}
+}
+
+// exportValue returns field of m as text: dates as YYYY-MM-DD for joined_at and
+// RFC 3339 for timestamps, so the output can be fed back to the CSV import.
+func exportValue(m *model.ChurchMember, field string) string {
+	switch field {
+	case "id":
+		return strconv.FormatInt(m.ID, 10)
+	case "name":
+		return m.Name
+	case "email":
+		return m.Email
+	case "phone":
+		return m.Phone
+	case "address":
+		return m.Address
+	case "biography":
+		return m.Biography
+	case "joined_at":
+		return m.JoinedAt.UTC().Format("2006-01-02")
+	case "created_at":
+		return m.CreatedAt.UTC().Format(time.RFC3339)
+	case "updated_at":
+		return m.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+type csvExporter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (e *csvExporter) write(m *model.ChurchMember) error {
+	record := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		record[i] = csvCell(exportValue(m, c))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExporter) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// formulaPrefixes are the first characters that make spreadsheet applications treat a
+// CSV cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// csvCell neutralises a value that a spreadsheet would evaluate by prefixing it with a
+// single quote; the import strips the quote again (see csvValue).
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// csvValue undoes csvCell.
+func csvValue(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// xlsxExporter writes a workbook with one sheet; IDs are number cells and everything else
+// text, so Excel never reinterprets phone numbers or evaluates cell contents.
+type xlsxExporter struct {
+	w       *xlsx.Writer
+	columns []string
+}
+
+func (e *xlsxExporter) write(m *model.ChurchMember) error {
+	cells := make([]interface{}, len(e.columns))
+	for i, c := range e.columns {
+		if c == "id" {
+			cells[i] = m.ID
+		} else {
+			cells[i] = exportValue(m, c)
+		}
+	}
+	return e.w.WriteValues(cells)
+}
+
+func (e *xlsxExporter) close() error { return e.w.Close() }
+
+// vcardExporter writes one vCard 4.0 (RFC 6350) per member. FN is always present as the
+// standard requires; email, phone, address, biography and updated_at become EMAIL, TEL,
+// ADR, NOTE and REV when selected. Other columns have no vCard property and are skipped.
+type vcardExporter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (e *vcardExporter) write(m *model.ChurchMember) error {
+	var b strings.Builder
+	line := func(s string) { b.WriteString(foldLine(s)) }
+	line("BEGIN:VCARD")
+	line("VERSION:4.0")
+	line("FN:" + vcardText(m.Name))
+	for _, c := range e.columns {
+		switch c {
+		case "email":
+			if m.Email != "" {
+				line("EMAIL:" + vcardText(m.Email))
+			}
+		case "phone":
+			if m.Phone != "" {
+				line("TEL;VALUE=text:" + vcardText(m.Phone))
+			}
+		case "address":
+			if m.Address != "" {
+				// Addresses are free text, so the whole value goes in the street component.
+				line("ADR:;;" + vcardText(m.Address) + ";;;;")
+			}
+		case "biography":
+			if m.Biography != "" {
+				line("NOTE:" + vcardText(m.Biography))
+			}
+		case "updated_at":
+			line("REV:" + m.UpdatedAt.UTC().Format("20060102T150405Z"))
+		}
+	}
+	line("END:VCARD")
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *vcardExporter) close() error { return nil }
+
+// vcardEscaper escapes text property values as RFC 6350 section 3.4 requires.
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func vcardText(s string) string { return vcardEscaper.Replace(s) }
+
+// foldLine terminates a content line with CRLF, folding it so no physical line is longer
+// than 75 octets; continuation lines start with a space. UTF-8 sequences are never split.
+func foldLine(s string) string {
+	const limit = 75
+	var b strings.Builder
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && s[cut]&0xC0 == 0x80 { // don't cut inside a UTF-8 sequence
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		width = limit - 1 // the leading space counts
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/internal/metrics"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// defaultImportBatch is the number of rows committed per transaction when ImportOptions.BatchSize is 0.
+const defaultImportBatch = 100
+
+// importFields are the member fields a CSV import can set, by JSON name.
+var importFields = []string{"name", "email", "phone", "address", "biography", "joined_at"}
+
+// importDateLayouts are the joined_at formats accepted in CSV files.
+var importDateLayouts = []string{"2006-01-02", time.RFC3339}
+
+// errDryRun rolls back the transaction of a dry-run batch after its writes have been checked.
+var errDryRun = errors.New("dry run")
+
+// ErrImportEmpty is returned for a CSV file without a header row.
+var ErrImportEmpty = apperror.Validation("IMPORT_EMPTY", "CSV file is empty; the first row must name the columns")
+
+// importRow is one data row of a CSV file, keyed by member field.
+type importRow struct {
+	line   int
+	values map[string]string
+}
+
+// ImportMembers reads church members from CSV and upserts them by email: a row whose
+// email matches a live member updates that member's mapped columns, and any other row
+// creates a member. Each row is checked against the same rules as CreateMember and
+// UpdateMember; rows that fail are listed in the report and skipped. Rows are written in
+// batches of opts.BatchSize, one transaction per batch; if a batch fails on a database
+// error the import stops with that error and earlier batches stay committed. With
+// opts.DryRun every batch is rolled back after it has been checked.
+func (s *ChurchMemberService) ImportMembers(ctx context.Context, in io.Reader, opts model.ImportOptions) (*model.ImportReport, error) {
+	if err := validation.Struct(opts); err != nil {
+		return nil, err
+	}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultImportBatch
+	}
+
+	cr := csv.NewReader(in)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrImportEmpty
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.KindValidation, "IMPORT_INVALID_CSV", "CSV header row is invalid")
+	}
+	columns, err := importColumns(header, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ImportReport{DryRun: opts.DryRun, Errors: []model.ImportRowError{}}
+	seen := map[string]int{} // email -> line of its first row
+	var batch []importRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Rows++
+			failRow(report, parseErr.StartLine, "", apperror.FieldError{Code: "csv", Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Rows++
+		line, _ := cr.FieldPos(0)
+		row := importRow{line: line, values: map[string]string{}}
+		for field, i := range columns {
+			if i < len(record) {
+				row.values[field] = strings.TrimSpace(csvValue(record[i]))
+			}
+		}
+		email := row.values["email"]
+		if first, ok := seen[email]; ok && email != "" {
+			failRow(report, line, email, apperror.FieldError{
+				Field: "email", Code: "duplicate", Message: fmt.Sprintf("email already appears on line %d", first),
+			})
+			continue
+		}
+		seen[email] = line
+
+		if batch = append(batch, row); len(batch) == batchSize {
+			if err := s.importBatch(ctx, batch, opts.DryRun, report); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := s.importBatch(ctx, batch, opts.DryRun, report); err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.DryRun {
+		metrics.MembersCreated.Add(float64(report.Created))
+		metrics.MembersUpdated.Add(float64(report.Updated))
+	}
+	logger.FromContext(ctx).Info("members imported", "dry_run", opts.DryRun, "rows", report.Rows,
+		"created", report.Created, "updated", report.Updated, "unchanged", report.Unchanged, "failed", report.Failed)
+	return report, nil
+}
+
+// importBatch upserts rows in one transaction and adds the outcome to report. In a dry
+// run the writes are made and then rolled back, so the counts match a real run.
+func (s *ChurchMemberService) importBatch(ctx context.Context, rows []importRow, dryRun bool, report *model.ImportReport) error {
+	var created, updated, unchanged int
+	var failed []model.ImportRowError
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		created, updated, unchanged, failed = 0, 0, 0, nil // the batch may be retried
+		for _, row := range rows {
+			existing, err := s.repo.GetByEmail(ctx, row.values["email"])
+			if err != nil {
+				return err
+			}
+			var m model.ChurchMember
+			if existing != nil {
+				m = *existing
+			}
+			fields := row.apply(&m)
+			if existing == nil && m.JoinedAt.IsZero() {
+				m.JoinedAt = time.Now().UTC()
+			}
+			if fields = append(fields, validation.Fields(&m)...); len(fields) > 0 {
+				failed = append(failed, model.ImportRowError{Line: row.line, Email: row.values["email"], Errors: fields})
+				continue
+			}
+			if existing == nil {
+				// Create runs in a savepoint, so a member created with the same email since
+				// the lookup fails only this row.
+				_, err := s.repo.Create(ctx, &m)
+				if errors.Is(emailTaken(err), ErrEmailTaken) {
+					failed = append(failed, model.ImportRowError{Line: row.line, Email: m.Email, Errors: ErrEmailTaken.Fields})
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				created++
+				continue
+			}
+			if err := s.repo.Patch(ctx, &m); err != nil {
+				return err
+			}
+			if m.Version == existing.Version {
+				unchanged++
+			} else {
+				updated++
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+	report.Created += created
+	report.Updated += updated
+	report.Unchanged += unchanged
+	report.Failed += len(failed)
+	report.Errors = append(report.Errors, failed...)
+	return nil
+}
+
+// apply copies the row's values onto m and returns the values that could not be parsed.
+// Fields without a column keep m's value, so an update only touches mapped columns.
+func (row importRow) apply(m *model.ChurchMember) []apperror.FieldError {
+	var fields []apperror.FieldError
+	for field, v := range row.values {
+		switch field {
+		case "name":
+			m.Name = v
+		case "email":
+			m.Email = v
+		case "phone":
+			m.Phone = v
+		case "address":
+			m.Address = v
+		case "biography":
+			m.Biography = v
+		case "joined_at":
+			if v == "" {
+				continue
+			}
+			t, err := parseImportDate(v)
+			if err != nil {
+				fields = append(fields, apperror.FieldError{Field: field, Code: "date", Message: "joined_at must be a date like 2006-01-02"})
+				continue
+			}
+			m.JoinedAt = t
+		}
+	}
+	return fields
+}
+
+func parseImportDate(v string) (time.Time, error) {
+	var err error
+	for _, layout := range importDateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, v); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// importColumns returns the index in header of each member field present in the file.
+// mapping overrides the default column, which is the one named like the field; header
+// names are matched case-insensitively. The email column is required.
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // byte order mark written by Excel
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, dup := index[name]; !dup {
+			index[name] = i
+		}
+	}
+
+	var fields []apperror.FieldError
+	mapped := make([]string, 0, len(mapping))
+	for field := range mapping {
+		mapped = append(mapped, field)
+	}
+	sort.Strings(mapped) // deterministic error order
+	for _, field := range mapped {
+		if !contains(importFields, field) {
+			fields = append(fields, apperror.FieldError{
+				Field: "columns." + field, Code: "unknown", Message: field + " is not an importable field (use " + strings.Join(importFields, ", ") + ")",
+			})
+		} else if _, ok := index[strings.ToLower(strings.TrimSpace(mapping[field]))]; !ok {
+			fields = append(fields, apperror.FieldError{
+				Field: "columns." + field, Code: "missing_column", Message: strconv.Quote(mapping[field]) + " is not a column of the CSV file",
+			})
+		}
+	}
+
+	columns := map[string]int{}
+	for _, field := range importFields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		if i, ok := index[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["email"]; !ok && mapping["email"] == "" {
+		fields = append(fields, apperror.FieldError{
+			Field: "columns.email", Code: "required", Message: "the CSV file needs an email column, or map one with columns[email]",
+		})
+	}
+	if err := validation.Error(fields); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// failRow records a row that was rejected before reaching the database.
+func failRow(report *model.ImportReport, line int, email string, field apperror.FieldError) {
+	report.Failed++
+	report.Errors = append(report.Errors, model.ImportRowError{Line: line, Email: email, Errors: []apperror.FieldError{field}})
+}
+
+// WriteImportReport writes the rows that failed to import as CSV, one line per problem:
+// line, email, field, code, message. It is the downloadable form of report.Errors.
+func WriteImportReport(w io.Writer, report *model.ImportReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"line", "email", "field", "code", "message"}); err != nil {
+		return err
+	}
+	for _, e := range report.Errors {
+		for _, f := range e.Errors {
+			if err := cw.Write([]string{strconv.Itoa(e.Line), e.Email, f.Field, f.Code, f.Message}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
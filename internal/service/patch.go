@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/example/golang-project/internal/apperror"
+	"github.com/example/golang-project/pkg/jsonpatch"
+)
+
+// Fields a patch may not change, by JSON name.
+var (
+	memberReadOnly = []string{"id", "created_at", "updated_at", "deleted_at", "version"}
+	userReadOnly   = []string{"id", "created_at", "deleted_at", "version"}
+)
+
+// applyPatch applies p to the JSON form of current and decodes the result into patched.
+// Changes to readOnly fields, unknown fields and values of the wrong type are reported
+// as validation errors; business rules are left to the caller.
+func applyPatch(p *jsonpatch.Patch, current, patched interface{}, readOnly []string) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	out, err := p.Apply(doc)
+	if err != nil {
+		return patchError(err)
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(doc, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, &after); err != nil {
+		return apperror.Validation(apperror.CodeInvalidPatch, "patched document must be a JSON object")
+	}
+	var fields []apperror.FieldError
+	for _, name := range readOnly {
+		if !reflect.DeepEqual(before[name], after[name]) {
+			fields = append(fields, apperror.FieldError{Field: name, Code: "read_only", Message: name + " cannot be changed"})
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.InvalidFields(fields)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(patched); err != nil {
+		return decodeError(err)
+	}
+	return nil
+}
+
+// patchError translates jsonpatch errors into domain errors.
+func patchError(err error) error {
+	var pe *jsonpatch.Error
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return apperror.Conflict(ErrPatchTestFailed.Code, "patch test failed: "+err.Error())
+	case errors.As(err, &pe):
+		return apperror.Validation(apperror.CodeInvalidPatch, "invalid patch: "+pe.Error())
+	default:
+		return err
+	}
+}
+
+// decodeError reports why a patched document does not fit the record as a field error.
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return apperror.InvalidFields([]apperror.FieldError{
+			{Field: typeErr.Field, Code: "type", Message: typeErr.Field + " must be of type " + typeErr.Type.String()},
+		})
+	}
+	// encoding/json has no typed error for unknown fields.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name = strings.Trim(name, `"`)
+		return apperror.InvalidFields([]apperror.FieldError{
+			{Field: name, Code: "unknown", Message: name + " is not a known field"},
+		})
+	}
+	return apperror.Wrap(err, apperror.KindValidation, apperror.CodeInvalidPatch, "patched document is invalid")
+}
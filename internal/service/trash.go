@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/golang-project/internal/metrics"
+	"github.com/example/golang-project/internal/model"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/logger"
+)
+
+// purgeBatchSize is how many trashed rows one purge statement removes at most.
+const purgeBatchSize = 500
+
+// ListTrash returns one page of deleted members, most recently deleted first.
+func (s *ChurchMemberService) ListTrash(ctx context.Context, page model.PageRequest) (*model.Page[*model.ChurchMember], error) {
+	if err := validation.Struct(page); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListTrash(ctx, page)
+	return list, pageError(err)
+}
+
+// RestoreMember takes a member out of the trash and returns it. It fails with
+// ErrEmailTaken if a live member has taken the email since the delete.
+func (s *ChurchMemberService) RestoreMember(ctx context.Context, id int64) (*model.ChurchMember, error) {
+	if id <= 0 {
+		return nil, ErrInvalidMemberID
+	}
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := s.repo.GetDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		if deleted == nil {
+			return ErrMemberNotFound
+		}
+		return s.repo.Restore(ctx, id)
+	})
+	if err != nil {
+		return nil, emailTaken(err)
+	}
+	metrics.MembersRestored.Inc()
+	logger.FromContext(ctx).Info("member restored", "member_id", id)
+	return s.GetMember(ctx, id)
+}
+
+// PurgeTrash permanently deletes members that were moved to the trash before cutoff
+// and returns how many were removed.
+func (s *ChurchMemberService) PurgeTrash(ctx context.Context, cutoff time.Time) (int64, error) {
+	total, err := purge(ctx, func(ctx context.Context) (int64, error) {
+		return s.repo.Purge(ctx, cutoff, purgeBatchSize)
+	})
+	metrics.MembersPurged.Add(float64(total))
+	if total > 0 {
+		logger.FromContext(ctx).Info("trashed members purged", "count", total, "cutoff", cutoff)
+	}
+	return total, err
+}
+
+// ListTrash returns one page of deleted users, most recently deleted first.
+func (s *UserService) ListTrash(ctx context.Context, page model.PageRequest) (*model.Page[*model.User], error) {
+	if err := validation.Struct(page); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListTrash(ctx, page)
+	return list, pageError(err)
+}
+
+// RestoreUser takes a user out of the trash and returns it. It fails with
+// ErrEmailTaken if a live user has taken the email since the delete.
+func (s *UserService) RestoreUser(ctx context.Context, id int64) (*model.User, error) {
+	if id <= 0 {
+		return nil, ErrInvalidUserID
+	}
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := s.repo.GetDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		if deleted == nil {
+			return ErrUserNotFound
+		}
+		return s.repo.Restore(ctx, id)
+	})
+	if err != nil {
+		return nil, emailTaken(err)
+	}
+	metrics.UsersRestored.Inc()
+	logger.FromContext(ctx).Info("user restored", "user_id", id)
+	return s.GetUser(ctx, id)
+}
+
+// PurgeTrash permanently deletes users that were moved to the trash before cutoff
+// and returns how many were removed.
+func (s *UserService) PurgeTrash(ctx context.Context, cutoff time.Time) (int64, error) {
+	total, err := purge(ctx, func(ctx context.Context) (int64, error) {
+		return s.repo.Purge(ctx, cutoff, purgeBatchSize)
+	})
+	metrics.UsersPurged.Add(float64(total))
+	if total > 0 {
+		logger.FromContext(ctx).Info("trashed users purged", "count", total, "cutoff", cutoff)
+	}
+	return total, err
+}
+
+// purge calls batch until it removes fewer than purgeBatchSize rows, ctx is cancelled
+// or it fails, and returns the total removed so far.
+func purge(ctx context.Context, batch func(context.Context) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, err := batch(ctx)
+		total += n
+		if err != nil || n < purgeBatchSize {
+			return total, err
+		}
+	}
+}
@@ -3,48 +3,205 @@ package service
 import (
 	"context"
 	"errors"
-	"strings"
 
+	"github.com/example/golang-project/internal/auth"
+	"github.com/example/golang-project/internal/metrics"
 	"github.com/example/golang-project/internal/model"
 	"github.com/example/golang-project/internal/repository"
+	"github.com/example/golang-project/internal/validation"
+	"github.com/example/golang-project/pkg/db"
+	"github.com/example/golang-project/pkg/jsonpatch"
+	"github.com/example/golang-project/pkg/logger"
 )
 
-// UserService contains business logic for users. It delegates persistence to the repository.
+// UserService contains business logic for users. It delegates persistence to the repository,
+// running reads and writes that must see the same data in one transaction through uow.
 type UserService struct {
 	repo *repository.UserRepository
+	uow  db.UnitOfWork
 }
 
 // NewUserService constructs a new UserService.
-func NewUserService(r *repository.UserRepository) *UserService {
-	return &UserService{repo: r}
+func NewUserService(r *repository.UserRepository, uow db.UnitOfWork) *UserService {
+	return &UserService{repo: r, uow: uow}
 }
 
 // CreateUser validates and creates a new user, returning the created ID.
 func (s *UserService) CreateUser(ctx context.Context, u *model.User) (int64, error) {
-	// In a .NET style you'd validate DTOs here; keep simple and delegate to repo.
-	if strings.TrimSpace(u.Name) == "" || strings.TrimSpace(u.Email) == "" {
-		return 0, errors.New("name and email are required")
+	fields := validation.Fields(u)
+	if u.Password == "" {
+		fields = append(fields, validation.Required("password"))
 	}
+	if err := validation.Error(fields); err != nil {
+		return 0, err
+	}
+	if u.Role == "" {
+		u.Role = string(auth.DefaultRole)
+	}
+	hash, err := hashPassword(u.Password)
+	if err != nil {
+		return 0, err
+	}
+	u.PasswordHash = hash
 
-	return s.repo.Create(ctx, u)
+	id, err := s.repo.Create(ctx, u)
+	if err != nil {
+		return 0, emailTaken(err)
+	}
+	metrics.UsersCreated.Inc()
+	logger.FromContext(ctx).Info("user created", "user_id", id)
+	return id, nil
 }
 
-// GetUser returns a user by ID.
+// GetUser returns a user by ID, or ErrUserNotFound.
 func (s *UserService) GetUser(ctx context.Context, id int64) (*model.User, error) {
-	return s.repo.GetByID(ctx, id)
+	if id <= 0 {
+		return nil, ErrInvalidUserID
+	}
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	return u, nil
 }
 
 // UpdateUser updates an existing user.
+// A non-empty Password also replaces the user's password; an empty Role keeps the current one.
+// A non-zero u.Version is the version the caller last read; if the user has changed since,
+// ErrUserModified is returned. On success u.Version holds the new version.
 func (s *UserService) UpdateUser(ctx context.Context, u *model.User) error {
-	return s.repo.Update(ctx, u)
+	if err := validation.Struct(u); err != nil {
+		return err
+	}
+	if u.Password != "" {
+		hash, err := hashPassword(u.Password)
+		if err != nil {
+			return err
+		}
+		u.PasswordHash = hash
+	}
+	if u.ID <= 0 {
+		return ErrInvalidUserID
+	}
+	version := u.Version
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		u.Version = version // Update overwrites it; a retried attempt must check the caller's version again
+		existing, err := s.repo.GetForUpdate(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrUserNotFound
+		}
+		if err := s.repo.Update(ctx, u); err != nil {
+			return err
+		}
+		if u.PasswordHash != "" {
+			u.Version, err = s.repo.UpdatePassword(ctx, u.ID, u.PasswordHash)
+		}
+		return err
+	})
+	if err != nil {
+		return versionError(emailTaken(err), ErrUserModified)
+	}
+	metrics.UsersUpdated.Inc()
+	logger.FromContext(ctx).Info("user updated", "user_id", u.ID)
+	return nil
 }
 
-// DeleteUser removes a user by ID.
-func (s *UserService) DeleteUser(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+// PatchUser applies a JSON Merge Patch or JSON Patch to a user and saves only the fields
+// it changed. Adding a password replaces it, as in UpdateUser, but the role cannot be
+// removed. version is checked as in UpdateUser. It returns the user as saved.
+func (s *UserService) PatchUser(ctx context.Context, id, version int64, p *jsonpatch.Patch) (*model.User, error) {
+	if id <= 0 {
+		return nil, ErrInvalidUserID
+	}
+	var patched model.User
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		patched = model.User{}
+		current, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrUserNotFound
+		}
+		if version != 0 && version != current.Version {
+			return ErrUserModified
+		}
+		if err := applyPatch(p, current, &patched, userReadOnly); err != nil {
+			return err
+		}
+		fields := validation.Fields(&patched)
+		if patched.Role == "" {
+			fields = append(fields, validation.Required("role"))
+		}
+		if err := validation.Error(fields); err != nil {
+			return err
+		}
+		if err := s.repo.Patch(ctx, &patched); err != nil {
+			return err
+		}
+		if patched.Password == "" {
+			return nil
+		}
+		hash, err := hashPassword(patched.Password)
+		if err != nil {
+			return err
+		}
+		patched.Password = ""
+		patched.Version, err = s.repo.UpdatePassword(ctx, id, hash)
+		return err
+	})
+	if err != nil {
+		return nil, emailTaken(err)
+	}
+	metrics.UsersUpdated.Inc()
+	logger.FromContext(ctx).Info("user patched", "user_id", id, "version", patched.Version)
+	return &patched, nil
+}
+
+// DeleteUser moves a user to the trash; see RestoreUser and PurgeTrash.
+// A non-zero version must match the user's current version, as in UpdateUser.
+func (s *UserService) DeleteUser(ctx context.Context, id, version int64) error {
+	if id <= 0 {
+		return ErrInvalidUserID
+	}
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrUserNotFound
+		}
+		return s.repo.Delete(ctx, id, version)
+	})
+	if err != nil {
+		return versionError(err, ErrUserModified)
+	}
+	metrics.UsersDeleted.Inc()
+	logger.FromContext(ctx).Info("user deleted", "user_id", id)
+	return nil
 }
 
-// ListUsers returns all users.
-func (s *UserService) ListUsers(ctx context.Context) ([]*model.User, error) {
-	return s.repo.List(ctx)
+// ListUsers returns one page of users ordered by id.
+func (s *UserService) ListUsers(ctx context.Context, page model.PageRequest) (*model.Page[*model.User], error) {
+	if err := validation.Struct(page); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, page)
+	return list, pageError(err)
+}
+
+// hashPassword hashes a new password, translating auth errors into domain errors.
+func hashPassword(password string) (string, error) {
+	hash, err := auth.HashPassword(password)
+	if errors.Is(err, auth.ErrPasswordTooShort) {
+		return "", ErrPasswordTooShort
+	}
+	return hash, err
 }
@@ -0,0 +1,275 @@
+// Package validation checks structs against their `validate` tags and reports every
+// failing field at once, so clients can fix a whole form in one round trip.
+//
+// Supported rules, comma separated:
+//
+//	required        string must be non-blank, other types non-zero
+//	min=N / max=N   string length in characters (after trimming), or numeric bounds
+//	maxbytes=N      string length in bytes, untrimmed (e.g. bcrypt's 72-byte limit)
+//	email           plausible email address
+//	phone           digits with optional +, spaces, dots, dashes and parentheses
+//	oneof=a b c     value must be one of the space-separated options
+//	after=D         time must not be before D (YYYY-MM-DD or "now")
+//	before=D        time must not be after D (YYYY-MM-DD or "now")
+//	gtefield=F      time must not be before the sibling field F
+//
+// Every rule except required skips zero values, so optional fields only need the
+// format rules. Pointer fields are checked through what they point to; nil counts as
+// zero. Field names in errors are taken from the json tag.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/example/golang-project/internal/apperror"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{5,18}[0-9]$`)
+
+	timeType = reflect.TypeOf(time.Time{})
+
+	// rulesCache holds the parsed rules per struct type; tags are only parsed once.
+	rulesCache sync.Map // reflect.Type -> []fieldRules
+)
+
+// Struct validates v (a struct or pointer to one) and returns an apperror with code
+// VALIDATION_FAILED listing every invalid field, or nil if v is valid.
+func Struct(v interface{}) error {
+	return Error(Fields(v))
+}
+
+// Fields validates v and returns the failing fields without wrapping them in an error,
+// so callers can append checks that depend on context (e.g. create vs update).
+func Fields(v interface{}) []apperror.FieldError {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: expected a struct, got %T", v))
+	}
+
+	var errs []apperror.FieldError
+	for _, f := range rulesFor(rv.Type()) {
+		fv := rv.Field(f.index)
+		for _, rl := range f.rules {
+			if fe, ok := rl.check(rv, fv, f.name); !ok {
+				errs = append(errs, fe)
+				break // one error per field is enough
+			}
+		}
+	}
+	return errs
+}
+
+// Error wraps fields in a VALIDATION_FAILED apperror, or returns nil if there are none.
+func Error(fields []apperror.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return apperror.InvalidFields(fields)
+}
+
+// Required returns the error Fields reports for a missing required field.
+func Required(field string) apperror.FieldError {
+	return apperror.FieldError{Field: field, Code: "required", Message: field + " is required"}
+}
+
+type fieldRules struct {
+	index int
+	name  string
+	rules []rule
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func rulesFor(t reflect.Type) []fieldRules {
+	if cached, ok := rulesCache.Load(t); ok {
+		return cached.([]fieldRules)
+	}
+
+	var out []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		f := fieldRules{index: i, name: jsonName(sf)}
+		for _, part := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if !knownRule(name) {
+				panic(fmt.Sprintf("validation: unknown rule %q on %s.%s", name, t.Name(), sf.Name))
+			}
+			if name == "gtefield" {
+				if _, ok := t.FieldByName(param); !ok {
+					panic(fmt.Sprintf("validation: gtefield on %s.%s refers to unknown field %q", t.Name(), sf.Name, param))
+				}
+			}
+			f.rules = append(f.rules, rule{name: name, param: param})
+		}
+		out = append(out, f)
+	}
+
+	rulesCache.Store(t, out)
+	return out
+}
+
+func knownRule(name string) bool {
+	switch name {
+	case "required", "min", "max", "maxbytes", "email", "phone", "oneof", "after", "before", "gtefield":
+		return true
+	}
+	return false
+}
+
+// jsonName returns the field's json name, falling back to the Go name.
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// check applies one rule to fv, a field of parent. Zero values only fail "required".
+func (rl rule) check(parent, fv reflect.Value, field string) (apperror.FieldError, bool) {
+	fail := func(format string, args ...interface{}) (apperror.FieldError, bool) {
+		return apperror.FieldError{Field: field, Code: rl.name, Message: field + " " + fmt.Sprintf(format, args...)}, false
+	}
+
+	if fv.Kind() == reflect.Pointer && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+	if isBlank(fv) {
+		if rl.name == "required" {
+			return Required(field), false
+		}
+		return apperror.FieldError{}, true
+	}
+
+	switch rl.name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(rl.param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validation: %s=%q on %s is not a number", rl.name, rl.param, field))
+		}
+		n, isString := measure(fv)
+		if rl.name == "min" && n < limit {
+			if isString {
+				return fail("must be at least %s characters", rl.param)
+			}
+			return fail("must be at least %s", rl.param)
+		}
+		if rl.name == "max" && n > limit {
+			if isString {
+				return fail("must not exceed %s characters", rl.param)
+			}
+			return fail("must not exceed %s", rl.param)
+		}
+
+	case "maxbytes":
+		limit, err := strconv.Atoi(rl.param)
+		if err != nil {
+			panic(fmt.Sprintf("validation: maxbytes=%q on %s is not a number", rl.param, field))
+		}
+		if len(fv.String()) > limit {
+			return fail("must not exceed %s bytes", rl.param)
+		}
+
+	case "email":
+		if !emailPattern.MatchString(strings.TrimSpace(fv.String())) {
+			return fail("must be a valid email address")
+		}
+
+	case "phone":
+		if !phonePattern.MatchString(strings.TrimSpace(fv.String())) {
+			return fail("must be a valid phone number")
+		}
+
+	case "oneof":
+		options := strings.Fields(rl.param)
+		value := fmt.Sprint(fv.Interface())
+		for _, o := range options {
+			if value == o {
+				return apperror.FieldError{}, true
+			}
+		}
+		return fail("must be one of %s", strings.Join(options, ", "))
+
+	case "after", "before":
+		t := fv.Interface().(time.Time)
+		bound := parseBound(rl.param)
+		if rl.name == "after" && t.Before(bound) {
+			if rl.param == "now" {
+				return fail("must not be in the past")
+			}
+			return fail("must not be before %s", rl.param)
+		}
+		if rl.name == "before" && t.After(bound) {
+			if rl.param == "now" {
+				return fail("must not be in the future")
+			}
+			return fail("must not be after %s", rl.param)
+		}
+
+	case "gtefield":
+		other := reflect.Indirect(parent.FieldByName(rl.param))
+		if other.IsValid() && other.Type() == timeType && fv.Type() == timeType {
+			if fv.Interface().(time.Time).Before(other.Interface().(time.Time)) {
+				sf, _ := parent.Type().FieldByName(rl.param)
+				return fail("must not be before %s", jsonName(sf))
+			}
+		}
+	}
+	return apperror.FieldError{}, true
+}
+
+// isBlank reports whether v is its zero value; whitespace-only strings count as blank.
+func isBlank(v reflect.Value) bool {
+	if v.Kind() == reflect.String {
+		return strings.TrimSpace(v.String()) == ""
+	}
+	return v.IsZero()
+}
+
+// measure returns the trimmed character count of strings, the length of slices and maps,
+// and the value of numbers.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(strings.TrimSpace(v.String()))), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false
+	}
+	panic(fmt.Sprintf("validation: min/max not supported on %s", v.Type()))
+}
+
+// parseBound resolves an after/before parameter: "now" or a YYYY-MM-DD date (UTC).
+func parseBound(param string) time.Time {
+	if param == "now" {
+		return time.Now()
+	}
+	t, err := time.Parse(dateLayout, param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: date bound %q must be %q or YYYY-MM-DD", param, "now"))
+	}
+	return t
+}
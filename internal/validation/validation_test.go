@@ -0,0 +1,218 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/golang-project/internal/apperror"
+)
+
+type sample struct {
+	Name     string     `json:"name" validate:"required,min=2,max=5"`
+	Password string     `json:"password" validate:"maxbytes=8"`
+	Email    string     `json:"email,omitempty" validate:"email"`
+	Phone    string     `json:"phone" validate:"phone"`
+	Role     string     `json:"role" validate:"oneof=admin staff"`
+	Limit    int        `json:"limit" validate:"min=1,max=100"`
+	Tags     []string   `json:"tags" validate:"max=2"`
+	Joined   time.Time  `json:"joined" validate:"after=1900-01-01,before=now"`
+	Start    time.Time  `json:"start"`
+	End      time.Time  `json:"end" validate:"gtefield=Start"`
+	Nick     *string    `json:"nick" validate:"min=2"`
+	Until    *time.Time `json:"until" validate:"gtefield=Start"`
+	ID       int64      `validate:"required"` // no json tag: errors use the Go name
+	Ignored  string     `json:"ignored" validate:"-"`
+}
+
+// valid returns a sample that passes every rule; tests change one field at a time.
+func valid() sample {
+	return sample{Name: "Ann", ID: 1}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func date(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(*sample)
+		field    string // "" when the sample must stay valid
+		code     string
+		contains string
+	}{
+		{"valid", func(*sample) {}, "", "", ""},
+
+		// required
+		{"required empty", func(s *sample) { s.Name = "" }, "name", "required", "name is required"},
+		{"required blank", func(s *sample) { s.Name = " \t " }, "name", "required", ""},
+		{"required zero number", func(s *sample) { s.ID = 0 }, "ID", "required", "ID is required"},
+
+		// min / max on strings count trimmed characters, not bytes
+		{"min string", func(s *sample) { s.Name = "A" }, "name", "min", "at least 2 characters"},
+		{"min counts after trimming", func(s *sample) { s.Name = " A " }, "name", "min", ""},
+		{"max string", func(s *sample) { s.Name = "Annabel" }, "name", "max", "must not exceed 5 characters"},
+		{"max string multibyte", func(s *sample) { s.Name = "Zoëëë" }, "", "", ""},
+		{"max string at limit", func(s *sample) { s.Name = "Annab" }, "", "", ""},
+		{"min number", func(s *sample) { s.Limit = -1 }, "limit", "min", "must be at least 1"},
+		{"max number", func(s *sample) { s.Limit = 101 }, "limit", "max", "must not exceed 100"},
+		{"number bounds inclusive", func(s *sample) { s.Limit = 100 }, "", "", ""},
+		{"zero number skips min", func(s *sample) { s.Limit = 0 }, "", "", ""},
+		{"max slice", func(s *sample) { s.Tags = []string{"a", "b", "c"} }, "tags", "max", "must not exceed 2"},
+
+		// maxbytes counts bytes, untrimmed
+		{"maxbytes ascii at limit", func(s *sample) { s.Password = "12345678" }, "", "", ""},
+		{"maxbytes ascii over", func(s *sample) { s.Password = "123456789" }, "password", "maxbytes", "must not exceed 8 bytes"},
+		{"maxbytes multibyte over", func(s *sample) { s.Password = "ééééé" }, "password", "maxbytes", ""}, // 5 characters, 10 bytes
+		{"maxbytes multibyte at limit", func(s *sample) { s.Password = "éééé" }, "", "", ""},
+		{"maxbytes emoji", func(s *sample) { s.Password = "🔑🔑🔑" }, "password", "maxbytes", ""}, // 12 bytes
+		{"maxbytes counts spaces", func(s *sample) { s.Password = "  123456" + " " }, "password", "maxbytes", ""},
+
+		// email
+		{"email valid", func(s *sample) { s.Email = "ann.b+tag@example.co.uk" }, "", "", ""},
+		{"email trimmed", func(s *sample) { s.Email = " ann@example.com " }, "", "", ""},
+		{"email missing at", func(s *sample) { s.Email = "ann.example.com" }, "email", "email", "valid email address"},
+		{"email missing tld", func(s *sample) { s.Email = "ann@example" }, "email", "email", ""},
+		{"email empty is optional", func(s *sample) { s.Email = "" }, "", "", ""},
+
+		// phone
+		{"phone valid", func(s *sample) { s.Phone = "+1 (555) 010-0100" }, "", "", ""},
+		{"phone letters", func(s *sample) { s.Phone = "555-CALL-NOW" }, "phone", "phone", "valid phone number"},
+		{"phone too short", func(s *sample) { s.Phone = "12345" }, "phone", "phone", ""},
+
+		// oneof
+		{"oneof match", func(s *sample) { s.Role = "staff" }, "", "", ""},
+		{"oneof mismatch", func(s *sample) { s.Role = "root" }, "role", "oneof", "must be one of admin, staff"},
+		{"oneof is case sensitive", func(s *sample) { s.Role = "Admin" }, "role", "oneof", ""},
+
+		// after / before
+		{"after bound inclusive", func(s *sample) { s.Joined = date("1900-01-01") }, "", "", ""},
+		{"after violated", func(s *sample) { s.Joined = date("1899-12-31") }, "joined", "after", "must not be before 1900-01-01"},
+		{"before now violated", func(s *sample) { s.Joined = time.Now().Add(time.Hour) }, "joined", "before", "must not be in the future"},
+
+		// gtefield
+		{"gtefield equal", func(s *sample) { s.Start, s.End = date("2024-01-01"), date("2024-01-01") }, "", "", ""},
+		{"gtefield after", func(s *sample) { s.Start, s.End = date("2024-01-01"), date("2024-02-01") }, "", "", ""},
+		{"gtefield before", func(s *sample) { s.Start, s.End = date("2024-02-01"), date("2024-01-01") }, "end", "gtefield", "must not be before start"},
+		{"gtefield zero end skipped", func(s *sample) { s.Start = date("2024-02-01") }, "", "", ""},
+
+		// pointers are checked through their target; nil counts as zero
+		{"nil pointer", func(s *sample) { s.Nick = nil }, "", "", ""},
+		{"pointer valid", func(s *sample) { s.Nick = ptr("Jo") }, "", "", ""},
+		{"pointer invalid", func(s *sample) { s.Nick = ptr("J") }, "nick", "min", "at least 2 characters"},
+		{"pointer to zero", func(s *sample) { s.Nick = ptr("") }, "", "", ""},
+		{"pointer gtefield", func(s *sample) { s.Start, s.Until = date("2024-02-01"), ptr(date("2024-01-01")) }, "until", "gtefield", ""},
+		{"pointer gtefield ok", func(s *sample) { s.Start, s.Until = date("2024-01-01"), ptr(date("2024-02-01")) }, "", "", ""},
+
+		{"ignored field", func(s *sample) { s.Ignored = strings.Repeat("x", 1000) }, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.change(&s)
+			fields := Fields(s)
+			if tt.field == "" {
+				if len(fields) != 0 {
+					t.Fatalf("Fields = %+v, want none", fields)
+				}
+				return
+			}
+			if len(fields) != 1 {
+				t.Fatalf("Fields = %+v, want one error for %s", fields, tt.field)
+			}
+			f := fields[0]
+			if f.Field != tt.field || f.Code != tt.code || !strings.Contains(f.Message, tt.contains) {
+				t.Errorf("got %+v, want field %q, code %q, message containing %q", f, tt.field, tt.code, tt.contains)
+			}
+		})
+	}
+}
+
+func TestStructAggregatesErrors(t *testing.T) {
+	s := sample{
+		Name:     "",         // required
+		Password: "ünïcödé!", // 8 characters, 12 bytes
+		Email:    "nope",     // email
+		Role:     "root",     // oneof
+		Limit:    500,        // max
+		Start:    date("2024-02-01"),
+		End:      date("2024-01-01"),         // gtefield
+		Joined:   date("1800-01-01"),         // after
+		Nick:     ptr("x"),                   // min
+		Ignored:  strings.Repeat("x", 10000), // not validated
+	}
+	err := Struct(&s)
+
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("Struct returned %T (%v), want *apperror.Error", err, err)
+	}
+	if appErr.Kind != apperror.KindValidation || appErr.Code != apperror.CodeValidation {
+		t.Errorf("kind %q, code %q; want %q, %q", appErr.Kind, appErr.Code, apperror.KindValidation, apperror.CodeValidation)
+	}
+	// Every failing field is reported once, in declaration order, with its first failing rule.
+	got := make([]string, len(appErr.Fields))
+	for i, f := range appErr.Fields {
+		got[i] = f.Field + ":" + f.Code
+	}
+	want := []string{"name:required", "password:maxbytes", "email:email", "role:oneof", "limit:max",
+		"joined:after", "end:gtefield", "nick:min", "ID:required"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v\nwant     %v", got, want)
+	}
+}
+
+func TestStructValueAndPointer(t *testing.T) {
+	s := valid()
+	s.Name = "A"
+	byValue, byPointer := Fields(s), Fields(&s)
+	if !reflect.DeepEqual(byValue, byPointer) || len(byValue) != 1 {
+		t.Errorf("Fields(v) = %+v, Fields(&v) = %+v; want the same single error", byValue, byPointer)
+	}
+	if err := Struct(valid()); err != nil {
+		t.Errorf("Struct(valid) = %v, want nil", err)
+	}
+	if err := Error(nil); err != nil {
+		t.Errorf("Error(nil) = %v, want nil", err)
+	}
+}
+
+func TestInvalidTagsPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not a struct", 42},
+		{"unknown rule", &struct {
+			A string `validate:"frobnicate"`
+		}{}},
+		{"gtefield unknown field", &struct {
+			A time.Time `validate:"gtefield=Nope"`
+		}{}},
+		{"min not a number", &struct {
+			A string `validate:"min=x"`
+		}{A: "a"}},
+		{"maxbytes not a number", &struct {
+			A string `validate:"maxbytes=x"`
+		}{A: "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic")
+				}
+			}()
+			Fields(tt.v)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+-- Migration: drop users table
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,4 @@
+-- Migration: Drop church_members table
+DROP INDEX IF EXISTS idx_church_members_joined_at;
+DROP INDEX IF EXISTS idx_church_members_email;
+DROP TABLE IF EXISTS church_members;
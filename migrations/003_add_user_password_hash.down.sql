@@ -0,0 +1,2 @@
+-- Migration: remove password hash from users
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
@@ -0,0 +1,2 @@
+-- Migration: add password hash to users for login
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT NOT NULL DEFAULT '';
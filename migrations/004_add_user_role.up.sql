@@ -0,0 +1,3 @@
+-- Migration: add role to users for role-based access control
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'staff', 'member', 'viewer'));
@@ -0,0 +1,7 @@
+-- Migration: restore the single-column joined_at index
+CREATE INDEX IF NOT EXISTS idx_church_members_joined_at ON church_members(joined_at);
+
+DROP INDEX IF EXISTS idx_church_members_name_id;
+DROP INDEX IF EXISTS idx_church_members_updated_at_id;
+DROP INDEX IF EXISTS idx_church_members_created_at_id;
+DROP INDEX IF EXISTS idx_church_members_joined_at_id;
@@ -0,0 +1,9 @@
+-- Migration: composite indexes backing keyset pagination and sorting of church members.
+-- Each ends in id, matching the tie-breaker the repository appends to every sort.
+CREATE INDEX IF NOT EXISTS idx_church_members_joined_at_id ON church_members(joined_at, id);
+CREATE INDEX IF NOT EXISTS idx_church_members_created_at_id ON church_members(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_church_members_updated_at_id ON church_members(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_church_members_name_id ON church_members(name, id);
+
+-- Superseded by idx_church_members_joined_at_id.
+DROP INDEX IF EXISTS idx_church_members_joined_at;
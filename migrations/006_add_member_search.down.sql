@@ -0,0 +1,3 @@
+-- Migration: remove full-text search from church members
+DROP INDEX IF EXISTS idx_church_members_search;
+ALTER TABLE church_members DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,13 @@
+-- Migration: full-text search over church members.
+-- Names and emails use the 'simple' configuration (no stemming, no stop words) and rank
+-- highest; address and biography are stemmed as English. Email punctuation is replaced
+-- with spaces so "john.doe@example.com" is found by "john", "doe" or "example".
+ALTER TABLE church_members ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', regexp_replace(coalesce(email, ''), '[@._+-]+', ' ', 'g')), 'B') ||
+        setweight(to_tsvector('english', coalesce(address, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(biography, '')), 'D')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_church_members_search ON church_members USING GIN (search_vector);
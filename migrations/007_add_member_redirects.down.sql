@@ -0,0 +1,2 @@
+-- Migration: drop member merge redirects
+DROP TABLE IF EXISTS member_redirects;
@@ -0,0 +1,9 @@
+-- Migration: remember which member a merged (deleted) member was folded into,
+-- so old IDs keep resolving to the surviving record.
+CREATE TABLE IF NOT EXISTS member_redirects (
+    old_id INTEGER PRIMARY KEY,
+    new_id INTEGER NOT NULL REFERENCES church_members(id) ON DELETE CASCADE,
+    merged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_redirects_new_id ON member_redirects(new_id);
@@ -0,0 +1,17 @@
+-- Migration: back to hard deletes.
+-- Trashed rows are removed first: without deleted_at they would come back as live
+-- records and could break the restored unique constraints.
+DROP INDEX IF EXISTS idx_users_deleted_at_id;
+DROP INDEX IF EXISTS idx_church_members_deleted_at_id;
+
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+DELETE FROM church_members WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_email_live;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_church_members_email_live;
+ALTER TABLE church_members ADD CONSTRAINT church_members_email_key UNIQUE (email);
+
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE church_members DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,16 @@
+-- Migration: soft delete for church members and users.
+-- Deleting sets deleted_at; rows stay restorable until the purge job removes them
+-- after the configured retention. Emails only need to be unique among live rows,
+-- so the table-wide unique constraints become partial unique indexes.
+ALTER TABLE church_members ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE church_members DROP CONSTRAINT IF EXISTS church_members_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_church_members_email_live ON church_members(email) WHERE deleted_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_live ON users(email) WHERE deleted_at IS NULL;
+
+-- Back the trash listings (newest deletion first) and the purge.
+CREATE INDEX IF NOT EXISTS idx_church_members_deleted_at_id ON church_members(deleted_at, id) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at_id ON users(deleted_at, id) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Migration: drop the audit trail
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
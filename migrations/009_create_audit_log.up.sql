@@ -0,0 +1,29 @@
+-- Migration: append-only audit trail of changes to church members and users.
+-- Rows are written by the repositories in the same transaction as the change they
+-- describe. There is no foreign key to the audited tables so history outlives purges.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    entity VARCHAR(20) NOT NULL,      -- member or user
+    entity_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,      -- create, update, delete, restore, merge or purge
+    actor_id BIGINT,                  -- NULL for background jobs
+    actor_email TEXT,
+    request_id TEXT,
+    changes JSONB NOT NULL DEFAULT '{}', -- {"field": {"old": ..., "new": ...}}
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at_id ON audit_log(created_at, id);
+
+-- Enforce append-only at the database level as well.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,3 @@
+-- Migration: drop row versions
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE church_members DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Migration: row versions for optimistic concurrency.
+-- Every write increments version; it is served as the ETag and checked against If-Match.
+ALTER TABLE church_members ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Migration: drop Idempotency-Key storage
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,16 @@
+-- Migration: Idempotency-Key storage for POST requests.
+-- A row is claimed before the first request runs (status NULL) and completed with its
+-- response afterwards; repeats with the same key replay that response until expires_at.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id BIGINT NOT NULL,          -- keys are scoped to the caller
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,        -- sha256 of method, path and body
+    status INTEGER,                   -- NULL while the first request is in flight
+    headers JSONB,
+    body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
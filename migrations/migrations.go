@@ -0,0 +1,13 @@
+// Package migrations embeds the versioned SQL migration files so the binary can
+// apply them without needing the migrations/ directory on disk.
+//
+// Files follow the NNN_description.up.sql / NNN_description.down.sql naming
+// convention; NNN is the version and must be unique and increasing.
+package migrations
+
+import "embed"
+
+// FS contains every *.sql file in this directory.
+//
+//go:embed *.sql
+var FS embed.FS
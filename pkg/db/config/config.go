@@ -3,15 +3,63 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"strings"
 )
 
 type Config struct {
 	Database struct {
 		ConnectionString string `json:"ConnectionString"`
+		// MigrationMode controls the startup schema check: "check" (default) refuses to
+		// start when migrations are pending, "auto" applies them, "off" skips the check.
+		MigrationMode string `json:"MigrationMode"`
+		// ReplicaConnectionStrings are read replicas of the primary. Reads outside
+		// transactions are spread over the ones passing health checks; without any, all
+		// traffic goes to ConnectionString.
+		ReplicaConnectionStrings []string `json:"ReplicaConnectionStrings"`
+		// ReplicaCheckInterval is how often replicas are health-checked (default 5s).
+		ReplicaCheckInterval Duration `json:"ReplicaCheckInterval"`
 	} `json:"Database"`
 	Server struct {
 		Addr string `json:"Addr"`
+		// Timeouts for the http.Server; zero values fall back to the defaults in server.Run.
+		ReadTimeout       Duration `json:"ReadTimeout"`
+		ReadHeaderTimeout Duration `json:"ReadHeaderTimeout"`
+		WriteTimeout      Duration `json:"WriteTimeout"`
+		IdleTimeout       Duration `json:"IdleTimeout"`
+		// ShutdownDelay keeps serving after /readyz starts failing so load balancers can react.
+		ShutdownDelay Duration `json:"ShutdownDelay"`
+		// ShutdownTimeout bounds how long in-flight requests may drain after SIGINT/SIGTERM.
+		ShutdownTimeout Duration `json:"ShutdownTimeout"`
 	} `json:"Server"`
+	Auth struct {
+		// JWTSecret signs access tokens (HS256); at least 32 bytes. Required: the server
+		// refuses to start without it. Set it through the JWT_SECRET env var rather than
+		// a committed file.
+		JWTSecret string `json:"JWTSecret"`
+		// Issuer is written to and required in the iss claim.
+		Issuer string `json:"Issuer"`
+		// AccessTokenTTL is how long issued tokens stay valid.
+		AccessTokenTTL Duration `json:"AccessTokenTTL"`
+		// PublicRoutes are reachable without a token. Entries ending in "/" match a path
+		// prefix, anything else must match exactly.
+		PublicRoutes []string `json:"PublicRoutes"`
+	} `json:"Auth"`
+	Retention struct {
+		// Trash is how long deleted members and users stay restorable before the purge job
+		// removes them for good (default 720h, i.e. 30 days). A negative value disables purging.
+		Trash Duration `json:"Trash"`
+		// PurgeInterval is how often the purge job runs (default 1h).
+		PurgeInterval Duration `json:"PurgeInterval"`
+		// IdempotencyKeys is how long a response stored for an Idempotency-Key is replayed
+		// (default 24h). Expired keys are deleted every PurgeInterval.
+		IdempotencyKeys Duration `json:"IdempotencyKeys"`
+	} `json:"Retention"`
+	Logging struct {
+		// Level is debug, info (default), warn or error; debug also logs every SQL statement.
+		Level string `json:"Level"`
+		// Format is json (default) or text.
+		Format string `json:"Format"`
+	} `json:"Logging"`
 }
 
 func Load(path string) (*Config, error) {
@@ -27,8 +75,20 @@ func Load(path string) (*Config, error) {
 	if v := os.Getenv("DB_CONN"); v != "" {
 		c.Database.ConnectionString = v
 	}
+	if v := os.Getenv("DB_MIGRATION_MODE"); v != "" {
+		c.Database.MigrationMode = v
+	}
+	if v := os.Getenv("DB_REPLICAS"); v != "" {
+		c.Database.ReplicaConnectionStrings = strings.Split(v, ",")
+	}
 	if v := os.Getenv("ADDR"); v != "" {
 		c.Server.Addr = v
 	}
+	if v := os.Getenv("JWT_SECRET"); v != "" {
+		c.Auth.JWTSecret = v
+	}
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		c.Logging.Level = v
+	}
 	return &c, nil
 }
@@ -10,6 +10,22 @@ import (
 // ConnectDB opens a sql.DB using the provided Postgres connection string.
 // Caller should call Close() on the returned *sql.DB when finished.
 func ConnectDB(connStr string) (*sql.DB, error) {
+	db, err := OpenDB(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ping to verify connectivity and credentials early.
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenDB is ConnectDB without the ping, for pools such as read replicas that may be
+// unreachable at startup without that being fatal (see Router.CheckReplicas).
+func OpenDB(connStr string) (*sql.DB, error) {
 	// database/sql manages a pool for us; lib/pq is the driver.
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -20,11 +36,5 @@ func ConnectDB(connStr string) (*sql.DB, error) {
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
-
-	// Ping to verify connectivity and credentials early.
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, err
-	}
 	return db, nil
 }
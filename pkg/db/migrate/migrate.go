@@ -0,0 +1,416 @@
+// Package migrate applies the versioned SQL migrations embedded in the binary and
+// tracks them in a schema_migrations table (similar to EF Core's __EFMigrationsHistory).
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Modes for Config.Database.MigrationMode, checked when the server starts.
+const (
+	// ModeCheck refuses to start when the schema is behind the embedded migrations (default).
+	ModeCheck = "check"
+	// ModeAuto applies pending migrations before the server starts.
+	ModeAuto = "auto"
+	// ModeOff skips the schema check entirely.
+	ModeOff = "off"
+)
+
+// advisoryLockID serializes migration runs across processes (arbitrary constant).
+const advisoryLockID = 7_240_311_001
+
+// ErrSchemaBehind is returned by EnsureUpToDate when migrations are pending.
+var ErrSchemaBehind = errors.New("database schema is behind the embedded migrations")
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of the up script
+}
+
+// Status describes a migration and whether it has been applied to the database.
+type Status struct {
+	Version          int64
+	Name             string
+	Applied          bool
+	AppliedAt        *time.Time
+	ChecksumMismatch bool
+}
+
+// Migrator applies and reverts migrations against a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads migrations from fsys (usually migrations.FS) and returns a Migrator.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads NNN_name.up.sql / NNN_name.down.sql pairs from the root of fsys,
+// sorted by version. Every version must have an up script; down is optional.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %q does not match NNN_name.(up|down).sql", e.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %q: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration version %d (%s) has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// LatestVersion returns the highest embedded migration version (0 if there are none).
+func (m *Migrator) LatestVersion() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion returns the highest applied migration version (0 if none are applied).
+// Unlike the other methods it never creates schema_migrations, so it is safe to call
+// from frequent probes; it fails if the table does not exist yet.
+func (m *Migrator) CurrentVersion(ctx context.Context) (int64, error) {
+	var current int64
+	err := m.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	return current, err
+}
+
+// Status reports every embedded migration along with its applied state.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if rec, ok := applied[mig.Version]; ok {
+			appliedAt := rec.appliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+			s.ChecksumMismatch = rec.checksum != mig.Checksum
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Pending returns the embedded migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verifyChecksums(applied); err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// EnsureUpToDate returns ErrSchemaBehind if any embedded migration has not been
+// applied, or an error if an applied migration was modified afterwards.
+func (m *Migrator) EnsureUpToDate(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending, next is %03d_%s", ErrSchemaBehind, len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// Up applies all pending migrations in version order, each in its own transaction.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verifyChecksums(applied); err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the most recently applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Redo reverts and re-applies the most recently applied migration.
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			redone = &mig
+			return nil
+		}
+		return errors.New("no applied migration to redo")
+	})
+	return redone, err
+}
+
+// Baseline records every unapplied migration up to and including version as applied,
+// without running it. It adopts a database whose schema was created before
+// schema_migrations existed, where re-running the scripts would fail or do nothing.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	i := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= version })
+	if i == len(m.migrations) || m.migrations[i].Version != version {
+		return nil, fmt.Errorf("no embedded migration has version %d", version)
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verifyChecksums(applied); err != nil {
+			return err
+		}
+		return inTx(ctx, conn, func(tx *sql.Tx) error {
+			for _, mig := range m.migrations[:i+1] {
+				if _, ok := applied[mig.Version]; ok {
+					continue
+				}
+				if err := record(ctx, tx, mig); err != nil {
+					return err
+				}
+				done = append(done, mig)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return done, nil
+}
+
+// apply runs an up script and records it in schema_migrations within one transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+			return fmt.Errorf("apply %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		return record(ctx, tx, mig)
+	})
+}
+
+// record marks mig as applied in schema_migrations.
+func record(ctx context.Context, tx *sql.Tx, mig Migration) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+		mig.Version, mig.Name, mig.Checksum, time.Now().UTC(),
+	)
+	return err
+}
+
+// revert runs a down script and removes the schema_migrations record within one transaction.
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("migration %03d_%s has no down script", mig.Version, mig.Name)
+	}
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+			return fmt.Errorf("revert %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+		return err
+	})
+}
+
+// verifyChecksums fails if an applied migration's up script no longer matches what was run.
+func (m *Migrator) verifyChecksums(applied map[int64]appliedRecord) error {
+	for _, mig := range m.migrations {
+		if rec, ok := applied[mig.Version]; ok && rec.checksum != mig.Checksum {
+			return fmt.Errorf("migration %03d_%s was modified after it was applied (checksum mismatch)", mig.Version, mig.Name)
+		}
+	}
+	return nil
+}
+
+type appliedRecord struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// querier is satisfied by both *sql.DB and *sql.Conn.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// applied creates schema_migrations if needed and returns the applied versions.
+func (m *Migrator) applied(ctx context.Context, q querier) (map[int64]appliedRecord, error) {
+	if _, err := q.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+		)`,
+	); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedRecord{}
+	for rows.Next() {
+		var version int64
+		var rec appliedRecord
+		if err := rows.Scan(&version, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = rec
+	}
+	return applied, rows.Err()
+}
+
+// withLock pins a connection and holds a Postgres advisory lock for the duration of fn,
+// so two instances starting at once don't apply the same migration twice.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+	return fn(conn)
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
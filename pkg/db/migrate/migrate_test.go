@@ -0,0 +1,157 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/example/golang-project/migrations"
+)
+
+func file(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_phone.up.sql":      file("ALTER TABLE users ADD phone TEXT;"),
+		"001_init.up.sql":           file("CREATE TABLE users (id BIGINT);"),
+		"001_init.down.sql":         file("DROP TABLE users;"),
+		"010_no_down.up.sql":        file("SELECT 1;"),
+		"README.md":                 file("not a migration"),
+		"seeds/003_seed.up.sql":     file("ignored: not at the root"),
+		"002_add_phone.down.sql":    file("ALTER TABLE users DROP phone;"),
+		"011_trailing_space.up.sql": file("SELECT 1; "),
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE users (id BIGINT);", Down: "DROP TABLE users;"},
+		{Version: 2, Name: "add_phone", Up: "ALTER TABLE users ADD phone TEXT;", Down: "ALTER TABLE users DROP phone;"},
+		{Version: 10, Name: "no_down", Up: "SELECT 1;"},
+		{Version: 11, Name: "trailing_space", Up: "SELECT 1; "},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d migrations, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		w.Checksum = sha(w.Up)
+		if got[i] != w {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+	if got[2].Checksum == got[3].Checksum {
+		t.Error("checksum ignores whitespace changes")
+	}
+}
+
+func TestChecksumCoversOnlyUpScript(t *testing.T) {
+	load := func(up, down string) string {
+		t.Helper()
+		ms, err := Load(fstest.MapFS{"001_init.up.sql": file(up), "001_init.down.sql": file(down)})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return ms[0].Checksum
+	}
+	base := load("CREATE TABLE a (id INT);", "DROP TABLE a;")
+	if got := load("CREATE TABLE a (id INT);", "DROP TABLE IF EXISTS a;"); got != base {
+		t.Errorf("editing the down script changed the checksum: %s != %s", got, base)
+	}
+	if got := load("CREATE TABLE a (id BIGINT);", "DROP TABLE a;"); got == base {
+		t.Error("editing the up script kept the checksum")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"bad name", fstest.MapFS{"init.up.sql": file("SELECT 1;")}, "does not match"},
+		{"bad direction", fstest.MapFS{"001_init.sideways.sql": file("SELECT 1;")}, "does not match"},
+		{"conflicting names", fstest.MapFS{"001_a.up.sql": file("SELECT 1;"), "001_b.down.sql": file("SELECT 1;")}, "conflicting names"},
+		{"down without up", fstest.MapFS{"001_init.down.sql": file("SELECT 1;")}, "no up script"},
+		{"empty up", fstest.MapFS{"001_init.up.sql": file("")}, "no up script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	m := &Migrator{migrations: []Migration{
+		{Version: 1, Name: "init", Checksum: sha("a")},
+		{Version: 2, Name: "add_phone", Checksum: sha("b")},
+	}}
+	tests := []struct {
+		name    string
+		applied map[int64]appliedRecord
+		wantErr string
+	}{
+		{"nothing applied", map[int64]appliedRecord{}, ""},
+		{"all match", map[int64]appliedRecord{1: {checksum: sha("a")}, 2: {checksum: sha("b")}}, ""},
+		{"pending ignored", map[int64]appliedRecord{1: {checksum: sha("a")}}, ""},
+		{"unknown applied version ignored", map[int64]appliedRecord{1: {checksum: sha("a")}, 99: {checksum: "x"}}, ""},
+		{"modified", map[int64]appliedRecord{1: {checksum: sha("a")}, 2: {checksum: sha("c")}}, "002_add_phone was modified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.verifyChecksums(tt.applied)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations checks the migrations shipped in the binary load cleanly and
+// have consecutive versions.
+func TestEmbeddedMigrations(t *testing.T) {
+	ms, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load(migrations.FS): %v", err)
+	}
+	if len(ms) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range ms {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+		if m.Checksum != sha(m.Up) {
+			t.Errorf("migration %03d_%s: checksum %s, want %s", m.Version, m.Name, m.Checksum, sha(m.Up))
+		}
+	}
+}
+
+// TestBaselineRejectsUnknownVersion runs without a database: the version is checked
+// before a connection is taken.
+func TestBaselineRejectsUnknownVersion(t *testing.T) {
+	m := &Migrator{migrations: []Migration{{Version: 1, Name: "init"}, {Version: 3, Name: "add_phone"}}}
+	for _, v := range []int64{0, 2, 4} {
+		if _, err := m.Baseline(context.Background(), v); err == nil || !strings.Contains(err.Error(), "no embedded migration") {
+			t.Errorf("Baseline(%d): err = %v, want an unknown version error", v, err)
+		}
+	}
+}
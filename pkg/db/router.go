@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// replicaCheckTimeout bounds one health check of one replica.
+const replicaCheckTimeout = 2 * time.Second
+
+// Router picks the pool a statement runs on: reads go to a healthy read replica, taken
+// in turn, and everything else to the primary. Reads fall back to the primary when no
+// replica is configured or healthy, and after the session in their context has written
+// (see WithSession), so a request always sees its own changes.
+//
+// Replicas start out of rotation and join it once CheckReplicas finds them reachable;
+// call it periodically.
+type Router struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// NewRouter creates a router over primary and any number of replicas.
+func NewRouter(primary *sql.DB, replicas ...*sql.DB) *Router {
+	r := &Router{primary: primary}
+	for _, db := range replicas {
+		r.replicas = append(r.replicas, &replica{db: db})
+	}
+	return r
+}
+
+// Primary returns the pool of the primary, which takes every write and every transaction.
+func (r *Router) Primary() *sql.DB {
+	return r.primary
+}
+
+// Reader returns the pool a read outside a transaction should run on.
+func (r *Router) Reader(ctx context.Context) *sql.DB {
+	if len(r.replicas) == 0 || wrote(ctx) {
+		return r.primary
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.db
+		}
+	}
+	return r.primary
+}
+
+// ReplicaHealth reports, for each replica in the order given to NewRouter, whether it
+// is in rotation.
+func (r *Router) ReplicaHealth() []bool {
+	health := make([]bool, len(r.replicas))
+	for i, rep := range r.replicas {
+		health[i] = rep.healthy.Load()
+	}
+	return health
+}
+
+// CheckReplicas pings every replica and takes those that fail out of rotation until a
+// later check succeeds. Changes are logged.
+func (r *Router) CheckReplicas(ctx context.Context) {
+	for i, rep := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+		err := rep.db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			slog.Info("db replica in rotation", "replica", i+1)
+		} else {
+			slog.Warn("db replica out of rotation, reading from the primary instead", "replica", i+1, "error", err)
+		}
+	}
+}
+
+// Close closes the replicas and the primary.
+func (r *Router) Close() error {
+	var errs []error
+	for _, rep := range r.replicas {
+		errs = append(errs, rep.db.Close())
+	}
+	errs = append(errs, r.primary.Close())
+	return errors.Join(errs...)
+}
+
+// session records whether a request has written.
+type session struct {
+	wrote atomic.Bool
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context that starts a read-your-writes session: once MarkWrite
+// has been called with it, or with a context derived from it, the Router sends its reads
+// to the primary, so they cannot miss the write on a lagging replica. The server gives
+// every request a session of its own.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// MarkWrite records that the session carried by ctx has written. It does nothing
+// outside a session.
+func MarkWrite(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// wrote reports whether the session carried by ctx has written.
+func wrote(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.wrote.Load()
+}
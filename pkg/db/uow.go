@@ -3,6 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // UnitOfWork is the transaction management interface (similar to .NET's IUnitOfWork).
@@ -19,6 +26,10 @@ type UnitOfWork interface {
 
 	// Tx returns the underlying *sql.Tx for direct access if needed.
 	Tx() *sql.Tx
+
+	// WithTransaction runs fn in a transaction carried by the context it is given; see
+	// UnitOfWorkImpl.WithTransaction.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
 }
 
 // UnitOfWorkImpl implements the UnitOfWork interface.
@@ -62,3 +73,224 @@ func (uow *UnitOfWorkImpl) Rollback(ctx context.Context) error {
 func (uow *UnitOfWorkImpl) Tx() *sql.Tx {
 	return uow.tx
 }
+
+// txState is the transaction stored in a context by WithTransaction.
+type txState struct {
+	tx    *sql.Tx
+	depth int // number of savepoints enclosing the current fn
+}
+
+type txKey struct{}
+
+// TxFromContext returns the transaction WithTransaction stored in ctx, or nil outside one.
+// Repositories run their statements on it, so everything called with that context takes
+// part in the transaction.
+func TxFromContext(ctx context.Context) *sql.Tx {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return st.tx
+	}
+	return nil
+}
+
+// WithTransaction runs fn with a context carrying a transaction. It commits if fn returns
+// nil and rolls back if fn returns an error or panics (the panic is then re-raised).
+//
+// If fn or the commit fails with a serialization failure (40001) or a deadlock (40P01),
+// the whole unit of work is run again after a jittered, exponentially growing delay,
+// up to MaxAttempts times in all; fn must therefore be safe to repeat, with side effects
+// such as metrics kept outside it. opts set the isolation level, read-only mode and
+// attempt cap.
+//
+// Called with a context that already carries a transaction, it nests: fn runs inside a
+// savepoint of the outer transaction, and an error from fn rolls back only what fn did
+// before the error is passed on. The outer call still decides whether anything commits
+// and is the one that retries; opts are ignored, since a savepoint shares the outer
+// transaction's isolation level.
+//
+// WithTransaction keeps no state in uow, so one UnitOfWorkImpl can be shared by every
+// caller; Begin, Commit, Rollback and Tx are unaffected by it.
+func (uow *UnitOfWorkImpl) WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if outer, ok := ctx.Value(txKey{}).(*txState); ok {
+		return savepoint(ctx, outer, fn)
+	}
+
+	o := txOptions{maxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for attempt := 1; ; attempt++ {
+		err := uow.run(ctx, fn, &sql.TxOptions{Isolation: o.isolation, ReadOnly: o.readOnly})
+		code := retryable(err)
+		if code == "" {
+			return err
+		}
+		if attempt >= o.maxAttempts {
+			retryStats.exhausted.Add(1)
+			return err
+		}
+		retryStats.count(code)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff(attempt)):
+		}
+	}
+}
+
+// run makes one attempt at the unit of work.
+func (uow *UnitOfWorkImpl) run(ctx context.Context, fn func(ctx context.Context) error, opts *sql.TxOptions) error {
+	tx, err := uow.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx})); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// savepoint runs fn inside a savepoint of outer's transaction.
+func savepoint(ctx context.Context, outer *txState, fn func(ctx context.Context) error) error {
+	inner := &txState{tx: outer.tx, depth: outer.depth + 1}
+	name := "sp_" + strconv.Itoa(inner.depth)
+	if _, err := outer.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			rollbackTo(ctx, outer.tx, name)
+			panic(p)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, inner)); err != nil {
+		// Undo fn's writes but keep the outer transaction usable; if even that fails the
+		// transaction is broken and the outer caller's next statement will report it.
+		rollbackTo(ctx, outer.tx, name)
+		return err
+	}
+	_, err := outer.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// rollbackTo undoes everything since savepoint name and then releases it, so that a
+// loop of failing nested units (such as a row-by-row import) doesn't pile up savepoints
+// for the rest of the transaction. Errors are ignored, as in savepoint.
+func rollbackTo(ctx context.Context, tx *sql.Tx, name string) {
+	ctx = context.WithoutCancel(ctx)
+	if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err == nil {
+		tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	}
+}
+
+// Retry limits of WithTransaction. The delay before attempt n+1 is drawn uniformly from
+// [0, min(retryBaseDelay*2^(n-1), retryMaxDelay)).
+const (
+	// DefaultMaxAttempts is how many times a unit of work runs at most unless MaxAttempts says otherwise.
+	DefaultMaxAttempts = 3
+
+	retryBaseDelay = 20 * time.Millisecond
+	retryMaxDelay  = time.Second
+)
+
+// SQLSTATEs after which a transaction can simply be run again.
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// TxOption configures WithTransaction.
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	isolation   sql.IsolationLevel
+	readOnly    bool
+	maxAttempts int
+}
+
+// Isolation runs the transaction at level, e.g. sql.LevelRepeatableRead or
+// sql.LevelSerializable. The default is the database's (READ COMMITTED for Postgres).
+func Isolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) { o.isolation = level }
+}
+
+// ReadOnly runs the transaction in read-only mode; writes in it fail.
+func ReadOnly() TxOption {
+	return func(o *txOptions) { o.readOnly = true }
+}
+
+// MaxAttempts caps how many times the unit of work runs; 1 disables retries.
+func MaxAttempts(n int) TxOption {
+	return func(o *txOptions) {
+		if n >= 1 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// retryable returns the SQLSTATE of err if running the transaction again may succeed,
+// and "" otherwise.
+func retryable(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch code := string(pqErr.Code); code {
+		case codeSerializationFailure, codeDeadlockDetected:
+			return code
+		}
+	}
+	return ""
+}
+
+// backoff returns the jittered delay before the attempt after attempt.
+func backoff(attempt int) time.Duration {
+	return rand.N(backoffLimit(attempt))
+}
+
+// backoffLimit returns the upper bound of backoff(attempt): retryBaseDelay doubled per
+// attempt, capped at retryMaxDelay. The cap is checked before shifting, so large
+// attempts cannot overflow.
+func backoffLimit(attempt int) time.Duration {
+	d := retryBaseDelay
+	for i := 1; i < attempt && d < retryMaxDelay; i++ {
+		d <<= 1
+	}
+	return min(d, retryMaxDelay)
+}
+
+// RetryStats counts the transactions WithTransaction ran again, process-wide.
+type RetryStats struct {
+	SerializationFailures uint64 // retries after 40001
+	Deadlocks             uint64 // retries after 40P01
+	Exhausted             uint64 // units of work that still failed after MaxAttempts
+}
+
+// retryCounters backs Retries.
+type retryCounters struct {
+	serialization, deadlock, exhausted atomic.Uint64
+}
+
+var retryStats retryCounters
+
+// count records a retry after an error with SQLSTATE code.
+func (c *retryCounters) count(code string) {
+	if code == codeDeadlockDetected {
+		c.deadlock.Add(1)
+	} else {
+		c.serialization.Add(1)
+	}
+}
+
+// Retries returns the retry counters, e.g. for metrics.
+func Retries() RetryStats {
+	return RetryStats{
+		SerializationFailures: retryStats.serialization.Load(),
+		Deadlocks:             retryStats.deadlock.Load(),
+		Exhausted:             retryStats.exhausted.Load(),
+	}
+}
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"serialization failure", &pq.Error{Code: "40001"}, "40001"},
+		{"deadlock", &pq.Error{Code: "40P01"}, "40P01"},
+		{"wrapped serialization failure", fmt.Errorf("update member: %w", &pq.Error{Code: "40001"}), "40001"},
+		{"wrapped deadlock", fmt.Errorf("a: %w", fmt.Errorf("b: %w", &pq.Error{Code: "40P01"})), "40P01"},
+		{"joined deadlock", errors.Join(errors.New("rollback failed"), &pq.Error{Code: "40P01"}), "40P01"},
+		{"unique violation", &pq.Error{Code: "23505"}, ""},
+		{"other class 40", &pq.Error{Code: "40002"}, ""},
+		{"lock not available", &pq.Error{Code: "55P03"}, ""},
+		{"not a pq error", errors.New("40001"), ""},
+		{"context canceled", context.Canceled, ""},
+		{"no rows", sql.ErrNoRows, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffLimit(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, retryBaseDelay},
+		{2, 2 * retryBaseDelay},
+		{3, 4 * retryBaseDelay},
+		{6, 32 * retryBaseDelay},
+		{7, retryMaxDelay}, // 64 * 20ms would be 1.28s
+		{8, retryMaxDelay},
+		{63, retryMaxDelay},
+		{64, retryMaxDelay}, // a plain shift would be 0 here
+		{65, retryMaxDelay},
+		{1 << 20, retryMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := backoffLimit(tt.attempt); got != tt.want {
+			t.Errorf("backoffLimit(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+	for attempt := 1; attempt <= 200; attempt++ {
+		limit := backoffLimit(attempt)
+		if limit <= 0 || limit > retryMaxDelay {
+			t.Fatalf("backoffLimit(%d) = %v, want within (0, %v]", attempt, limit, retryMaxDelay)
+		}
+		if prev := backoffLimit(attempt - 1); attempt > 1 && limit < prev {
+			t.Fatalf("backoffLimit(%d) = %v is below backoffLimit(%d) = %v", attempt, limit, attempt-1, prev)
+		}
+		if d := backoff(attempt); d < 0 || d >= limit {
+			t.Fatalf("backoff(%d) = %v, want within [0, %v)", attempt, d, limit)
+		}
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{-1, DefaultMaxAttempts},
+		{0, DefaultMaxAttempts},
+		{1, 1},
+		{5, 5},
+	}
+	for _, tt := range tests {
+		o := txOptions{maxAttempts: DefaultMaxAttempts}
+		MaxAttempts(tt.n)(&o)
+		if o.maxAttempts != tt.want {
+			t.Errorf("MaxAttempts(%d): maxAttempts = %d, want %d", tt.n, o.maxAttempts, tt.want)
+		}
+	}
+}
+
+func TestWithTransactionRetries(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	deadlock := &pq.Error{Code: "40P01"}
+	other := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		opts      []TxOption
+		errs      []error // returned by fn on each attempt; nil after the list runs out
+		wantRuns  int
+		wantErr   error
+		wantRetry RetryStats // growth of Retries()
+	}{
+		{"success", nil, nil, 1, nil, RetryStats{}},
+		{"other error not retried", nil, []error{other}, 1, other, RetryStats{}},
+		{"retried until success", nil, []error{serialization, deadlock}, 3, nil,
+			RetryStats{SerializationFailures: 1, Deadlocks: 1}},
+		{"wrapped error retried", nil, []error{fmt.Errorf("save: %w", serialization)}, 2, nil,
+			RetryStats{SerializationFailures: 1}},
+		{"exhausted returns last error", nil, []error{serialization, serialization, deadlock, nil}, 3, deadlock,
+			RetryStats{SerializationFailures: 2, Exhausted: 1}},
+		{"retry then other error", nil, []error{deadlock, other, nil}, 2, other,
+			RetryStats{Deadlocks: 1}},
+		{"MaxAttempts(1) disables retries", []TxOption{MaxAttempts(1)}, []error{serialization, nil}, 1, serialization,
+			RetryStats{Exhausted: 1}},
+		{"MaxAttempts(0) keeps the default", []TxOption{MaxAttempts(0)}, []error{serialization, serialization, serialization, nil}, DefaultMaxAttempts, serialization,
+			RetryStats{SerializationFailures: DefaultMaxAttempts - 1, Exhausted: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fake fakeDB
+			uow := NewUnitOfWork(sql.OpenDB(&fake))
+			before := Retries()
+			runs := 0
+			err := uow.WithTransaction(context.Background(), func(ctx context.Context) error {
+				runs++
+				if TxFromContext(ctx) == nil {
+					t.Error("fn ran without a transaction in its context")
+				}
+				if runs <= len(tt.errs) {
+					return tt.errs[runs-1]
+				}
+				return nil
+			}, tt.opts...)
+
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("fn ran %d times, want %d", runs, tt.wantRuns)
+			}
+			if fake.commits+fake.rollbacks != runs {
+				t.Errorf("%d commits and %d rollbacks for %d runs", fake.commits, fake.rollbacks, runs)
+			}
+			wantCommits := 0
+			if tt.wantErr == nil {
+				wantCommits = 1
+			}
+			if fake.commits != wantCommits {
+				t.Errorf("commits = %d, want %d", fake.commits, wantCommits)
+			}
+			after := Retries()
+			got := RetryStats{
+				SerializationFailures: after.SerializationFailures - before.SerializationFailures,
+				Deadlocks:             after.Deadlocks - before.Deadlocks,
+				Exhausted:             after.Exhausted - before.Exhausted,
+			}
+			if got != tt.wantRetry {
+				t.Errorf("Retries() grew by %+v, want %+v", got, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestWithTransactionRetriesCommitFailure(t *testing.T) {
+	fake := fakeDB{commitErrs: []error{&pq.Error{Code: "40001"}}}
+	uow := NewUnitOfWork(sql.OpenDB(&fake))
+	runs := 0
+	err := uow.WithTransaction(context.Background(), func(ctx context.Context) error {
+		runs++
+		return nil
+	})
+	if err != nil || runs != 2 || fake.commits != 2 {
+		t.Errorf("err = %v, runs = %d, commits = %d; want nil, 2, 2", err, runs, fake.commits)
+	}
+}
+
+func TestWithTransactionStopsWhenContextEnds(t *testing.T) {
+	var fake fakeDB
+	uow := NewUnitOfWork(sql.OpenDB(&fake))
+	ctx, cancel := context.WithCancel(context.Background())
+	serialization := &pq.Error{Code: "40001"}
+	runs := 0
+	err := uow.WithTransaction(ctx, func(ctx context.Context) error {
+		runs++
+		cancel()
+		return serialization
+	}, MaxAttempts(10))
+	if err != serialization || runs != 1 {
+		t.Errorf("err = %v, runs = %d; want the serialization failure after 1 run", err, runs)
+	}
+}
+
+// fakeDB is a database/sql driver whose transactions do nothing; commitErrs are
+// returned by successive commits.
+type fakeDB struct {
+	commitErrs []error
+	commits    int
+	rollbacks  int
+}
+
+func (d *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{d}, nil }
+func (d *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB: no statements")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx(c), nil }
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error {
+	tx.db.commits++
+	if n := tx.db.commits; n <= len(tx.db.commitErrs) {
+		return tx.db.commitErrs[n-1]
+	}
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.rollbacks++
+	return nil
+}
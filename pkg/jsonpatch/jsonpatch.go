@@ -0,0 +1,421 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902)
+// documents to JSON values, for PATCH endpoints that update only the fields a client sends.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media types of the two patch formats.
+const (
+	MergePatchType = "application/merge-patch+json"
+	PatchType      = "application/json-patch+json"
+)
+
+// ErrUnsupportedType is returned by Parse for a content type that is neither patch format.
+var ErrUnsupportedType = errors.New("jsonpatch: unsupported content type")
+
+// ErrTestFailed is wrapped by the Error returned when a JSON Patch test operation fails.
+var ErrTestFailed = errors.New("test operation failed")
+
+// Error reports a patch that is malformed or cannot be applied to the document.
+// Its message is meant for the client.
+type Error struct {
+	msg string
+	err error
+}
+
+func (e *Error) Error() string { return e.msg }
+
+// Unwrap returns ErrTestFailed for failed test operations and nil otherwise.
+func (e *Error) Unwrap() error { return e.err }
+
+func errorf(format string, args ...interface{}) *Error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
+
+// Patch is a parsed merge patch or JSON Patch.
+type Patch struct {
+	merge interface{} // set for a merge patch
+	ops   []operation // set for a JSON Patch
+}
+
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+
+	value interface{}
+}
+
+// Parse parses body as a patch of the given content type. It returns ErrUnsupportedType
+// for other content types and an *Error for malformed patches.
+func Parse(contentType string, body []byte) (*Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	switch mediaType {
+	case MergePatchType:
+		var merge interface{}
+		if err := decode(body, &merge); err != nil {
+			return nil, errorf("merge patch is not valid JSON")
+		}
+		return &Patch{merge: merge}, nil
+	case PatchType:
+		var ops []operation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return nil, errorf("JSON Patch must be an array of operations")
+		}
+		for i := range ops {
+			if err := ops[i].check(); err != nil {
+				return nil, errorf("operation %d: %s", i, err.msg)
+			}
+		}
+		return &Patch{ops: ops}, nil
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+// check validates the members of a JSON Patch operation and decodes its value.
+func (op *operation) check() *Error {
+	switch op.Op {
+	case "add", "remove", "replace", "move", "copy", "test":
+	default:
+		return errorf("unknown op %q", op.Op)
+	}
+	if op.Path == nil {
+		return errorf("path is required")
+	}
+	if _, err := pointer(*op.Path); err != nil {
+		return err
+	}
+	switch op.Op {
+	case "move", "copy":
+		if op.From == nil {
+			return errorf("from is required for %s", op.Op)
+		}
+		if _, err := pointer(*op.From); err != nil {
+			return err
+		}
+		if op.Op == "move" && strings.HasPrefix(*op.Path, *op.From+"/") {
+			return errorf("cannot move %q into one of its own children", *op.From)
+		}
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errorf("value is required for %s", op.Op)
+		}
+		if err := decode(op.Value, &op.value); err != nil {
+			return errorf("value is not valid JSON")
+		}
+	}
+	return nil
+}
+
+// Apply applies p to the JSON document doc and returns the patched document. A JSON
+// Patch is applied atomically: if any operation fails, an *Error is returned and doc is
+// left as it was.
+func (p *Patch) Apply(doc []byte) ([]byte, error) {
+	var v interface{}
+	if err := decode(doc, &v); err != nil {
+		return nil, err
+	}
+	if p.ops == nil {
+		return json.Marshal(mergePatch(v, p.merge))
+	}
+	for i, op := range p.ops {
+		var err *Error
+		if v, err = op.apply(v); err != nil {
+			return nil, &Error{msg: fmt.Sprintf("operation %d: %s", i, err.msg), err: err.err}
+		}
+	}
+	return json.Marshal(v)
+}
+
+// mergePatch implements the MergePatch algorithm of RFC 7396: objects are merged key by
+// key, null removes a key and anything else replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// apply runs one operation against doc and returns the new document.
+func (op *operation) apply(doc interface{}) (interface{}, *Error) {
+	path, _ := pointer(*op.Path)
+	switch op.Op {
+	case "add":
+		return add(doc, path, clone(op.value))
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "replace":
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		doc, _, err := remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, clone(op.value))
+	case "move":
+		from, _ := pointer(*op.From)
+		doc, v, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case "copy":
+		from, _ := pointer(*op.From)
+		v, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, clone(v))
+	default: // test
+		v, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(v, op.value) {
+			return nil, &Error{msg: fmt.Sprintf("value at %q does not match", *op.Path), err: ErrTestFailed}
+		}
+		return doc, nil
+	}
+}
+
+// pointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+func pointer(s string) ([]string, *Error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, errorf("path %q must be empty or start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// get returns the value at path.
+func get(doc interface{}, path []string) (interface{}, *Error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, notFound(path)
+			}
+			doc = v
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, notFound(path)
+		}
+	}
+	return doc, nil
+}
+
+// add sets the member named by the last token of path, or inserts into an array, and
+// returns the new document. Adding at the root replaces the whole document.
+func add(doc interface{}, path []string, value interface{}) (interface{}, *Error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(container interface{}, token string) (interface{}, *Error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i := len(node)
+			if token != "-" {
+				var err *Error
+				if i, err = index(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		default:
+			return nil, notFound(path)
+		}
+	})
+}
+
+// remove deletes the value at path and returns the new document and the removed value.
+func remove(doc interface{}, path []string) (interface{}, interface{}, *Error) {
+	if len(path) == 0 {
+		return nil, nil, errorf("cannot remove the whole document")
+	}
+	var removed interface{}
+	doc, err := update(doc, path, func(container interface{}, token string) (interface{}, *Error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, notFound(path)
+			}
+			removed = v
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[i]
+			return append(node[:i:i], node[i+1:]...), nil
+		default:
+			return nil, notFound(path)
+		}
+	})
+	return doc, removed, err
+}
+
+// update walks to the parent of the last token of path, replaces it with the result of
+// fn and returns the new document. Arrays are rebuilt on the way back up because an
+// insert or removal may reallocate them.
+func update(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, *Error)) (interface{}, *Error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = update(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		i, _ := index(path[0], len(node)-1)
+		node[i] = child
+	}
+	return doc, nil
+}
+
+// index parses an array index token, which must be between 0 and max.
+func index(token string, max int) (int, *Error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, errorf("array index %q is out of range", token)
+	}
+	return i, nil
+}
+
+func notFound(path []string) *Error {
+	escaped := make([]string, len(path))
+	for i, t := range path {
+		escaped[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(t)
+	}
+	return errorf("path %q does not exist", "/"+strings.Join(escaped, "/"))
+}
+
+// decode unmarshals JSON keeping numbers exact, so IDs survive a round trip.
+func decode(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// clone deep-copies a decoded JSON value, so a value added twice is never shared.
+func clone(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			out[k] = clone(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(node))
+		for i, child := range node {
+			out[i] = clone(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// equal compares decoded JSON values as RFC 6902 test does; numbers are equal when
+// their values are, so 1 matches 1.0.
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+		return errx == nil && erry == nil && fx == fy
+	default:
+		return a == b
+	}
+}
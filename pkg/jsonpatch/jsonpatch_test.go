@@ -0,0 +1,174 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add replaces member", `{"a":1}`, `[{"op":"add","path":"/a","value":[1]}]`, `{"a":[1]}`},
+		{"add at root", `{"a":1}`, `[{"op":"add","path":"","value":[]}]`, `[]`},
+		{"add array index", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"add array start", `{"a":[1]}`, `[{"op":"add","path":"/a/0","value":0}]`, `{"a":[0,1]}`},
+		{"add array end by index", `{"a":[1]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2]}`},
+		{"add array dash", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"add nested in array", `{"a":[{"b":1}]}`, `[{"op":"add","path":"/a/0/c","value":2}]`, `{"a":[{"b":1,"c":2}]}`},
+		{"add escaped slash", `{}`, `[{"op":"add","path":"/a~1b","value":1}]`, `{"a/b":1}`},
+		{"add escaped tilde", `{}`, `[{"op":"add","path":"/a~0b","value":1}]`, `{"a~b":1}`},
+		{"tilde escape not reapplied", `{}`, `[{"op":"add","path":"/~01","value":1}]`, `{"~1":1}`},
+		{"remove member", `{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{"remove array index", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{"remove escaped", `{"a/b":1,"c~d":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"remove","path":"/c~0d"}]`, `{}`},
+		{"replace", `{"a":{"b":1}}`, `[{"op":"replace","path":"/a/b","value":"x"}]`, `{"a":{"b":"x"}}`},
+		{"replace array index", `[1,2]`, `[{"op":"replace","path":"/1","value":3}]`, `[1,3]`},
+		{"move member", `{"a":1}`, `[{"op":"move","from":"/a","path":"/b"}]`, `{"b":1}`},
+		{"move within array", `{"a":[1,2,3]}`, `[{"op":"move","from":"/a/0","path":"/a/-"}]`, `{"a":[2,3,1]}`},
+		{"move between arrays", `{"a":[1,2],"b":[]}`, `[{"op":"move","from":"/a/1","path":"/b/0"}]`, `{"a":[1],"b":[2]}`},
+		{"copy member", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"copy into array", `{"a":[1,2]}`, `[{"op":"copy","from":"/a/1","path":"/a/0"}]`, `{"a":[2,1,2]}`},
+		{"copy is deep", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"test passes", `{"a":[1,{"b":"x"}]}`, `[{"op":"test","path":"/a","value":[1,{"b":"x"}]}]`, `{"a":[1,{"b":"x"}]}`},
+		{"test array index", `{"a":[1,2]}`, `[{"op":"test","path":"/a/1","value":2}]`, `{"a":[1,2]}`},
+		{"test numbers by value", `{"a":1}`, `[{"op":"test","path":"/a","value":1.0}]`, `{"a":1}`},
+		{"test escaped", `{"a/b":1}`, `[{"op":"test","path":"/a~1b","value":1}]`, `{"a/b":1}`},
+		{"large numbers exact", `{"id":9007199254740993}`, `[{"op":"add","path":"/x","value":1}]`, `{"id":9007199254740993,"x":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(PatchType, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := p.Apply([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		doc        string
+		patch      string
+		testFailed bool
+	}{
+		{"add missing parent", `{}`, `[{"op":"add","path":"/a/b","value":1}]`, false},
+		{"add index past end", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":1}]`, false},
+		{"add negative index", `{"a":[1]}`, `[{"op":"add","path":"/a/-1","value":1}]`, false},
+		{"add leading zero index", `{"a":[1,2]}`, `[{"op":"add","path":"/a/01","value":1}]`, false},
+		{"remove missing member", `{}`, `[{"op":"remove","path":"/a"}]`, false},
+		{"remove dash", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`, false},
+		{"remove index past end", `{"a":[1]}`, `[{"op":"remove","path":"/a/1"}]`, false},
+		{"remove root", `{}`, `[{"op":"remove","path":""}]`, false},
+		{"replace missing member", `{}`, `[{"op":"replace","path":"/a","value":1}]`, false},
+		{"move missing from", `{}`, `[{"op":"move","from":"/a","path":"/b"}]`, false},
+		{"copy missing from", `{}`, `[{"op":"copy","from":"/a","path":"/b"}]`, false},
+		{"test mismatch", `{"a":1}`, `[{"op":"test","path":"/a","value":2}]`, true},
+		{"test type mismatch", `{"a":"1"}`, `[{"op":"test","path":"/a","value":1}]`, true},
+		{"test array length", `{"a":[1]}`, `[{"op":"test","path":"/a","value":[1,2]}]`, true},
+		{"test missing path", `{}`, `[{"op":"test","path":"/a","value":1}]`, false},
+		{"fails after earlier ops", `{"a":1}`, `[{"op":"remove","path":"/a"},{"op":"test","path":"/a","value":1}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(PatchType, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			_, err = p.Apply([]byte(tt.doc))
+			var pe *Error
+			if !errors.As(err, &pe) {
+				t.Fatalf("Apply error = %v, want *Error", err)
+			}
+			if got := errors.Is(err, ErrTestFailed); got != tt.testFailed {
+				t.Errorf("errors.Is(err, ErrTestFailed) = %v, want %v (err: %v)", got, tt.testFailed, err)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		unsupported bool
+	}{
+		{"plain json", "application/json", `{}`, true},
+		{"bad content type", ";;", `{}`, true},
+		{"merge patch not json", MergePatchType, `{`, false},
+		{"merge patch trailing data", MergePatchType, `{} {}`, false},
+		{"patch not array", PatchType, `{"op":"add"}`, false},
+		{"unknown op", PatchType, `[{"op":"frob","path":"/a"}]`, false},
+		{"missing path", PatchType, `[{"op":"remove"}]`, false},
+		{"relative path", PatchType, `[{"op":"remove","path":"a"}]`, false},
+		{"missing value", PatchType, `[{"op":"add","path":"/a"}]`, false},
+		{"missing from", PatchType, `[{"op":"copy","path":"/a"}]`, false},
+		{"move into child", PatchType, `[{"op":"move","from":"/a","path":"/a/b"}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.contentType, []byte(tt.body))
+			if tt.unsupported {
+				if err != ErrUnsupportedType {
+					t.Errorf("err = %v, want ErrUnsupportedType", err)
+				}
+				return
+			}
+			var pe *Error
+			if !errors.As(err, &pe) {
+				t.Errorf("err = %v, want *Error", err)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Cases from RFC 7396, appendix A.
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			p, err := Parse(MergePatchType+"; charset=utf-8", []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := p.Apply([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
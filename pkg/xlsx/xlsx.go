@@ -0,0 +1,137 @@
+// Package xlsx writes single-sheet Office Open XML workbooks (.xlsx) row by row, so large
+// tables can be streamed without holding them in memory. Cells are inline strings or
+// numbers; there are no styles, formulas or shared strings.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of .xlsx files.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Parts written before the sheet; they don't depend on the data.
+var staticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// Writer streams rows into the first and only sheet of a workbook. Call Close to finish
+// the file; until then the output is not a valid workbook.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+	err   error
+}
+
+// NewWriter starts a workbook whose sheet is named sheetName.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range staticParts {
+		if err := writePart(zw, part.name, part.body); err != nil {
+			return nil, err
+		}
+	}
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(sheetName))
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := writePart(zw, "xl/workbook.xml", workbook); err != nil {
+		return nil, err
+	}
+
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &Writer{zw: zw, sheet: bufio.NewWriter(sw)}
+	xw.write(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return xw, xw.err
+}
+
+// WriteRow appends a row of text cells.
+func (w *Writer) WriteRow(cells []string) error {
+	values := make([]interface{}, len(cells))
+	for i, c := range cells {
+		values[i] = c
+	}
+	return w.WriteValues(values)
+}
+
+// WriteValues appends a row. int, int64 and float64 values become number cells, strings
+// text cells and nil an empty cell; other types are an error.
+func (w *Writer) WriteValues(cells []interface{}) error {
+	w.rows++
+	w.write(`<row r="` + strconv.Itoa(w.rows) + `">`)
+	for _, c := range cells {
+		switch v := c.(type) {
+		case nil:
+			w.write(`<c/>`)
+		case int:
+			w.write(`<c><v>` + strconv.Itoa(v) + `</v></c>`)
+		case int64:
+			w.write(`<c><v>` + strconv.FormatInt(v, 10) + `</v></c>`)
+		case float64:
+			w.write(`<c><v>` + strconv.FormatFloat(v, 'g', -1, 64) + `</v></c>`)
+		case string:
+			w.write(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if w.err == nil {
+				w.err = xml.EscapeText(w.sheet, []byte(v))
+			}
+			w.write(`</t></is></c>`)
+		default:
+			w.err = fmt.Errorf("xlsx: unsupported cell value of type %T", c)
+		}
+	}
+	w.write(`</row>`)
+	return w.err
+}
+
+// Close finishes the sheet and the zip archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	w.write(`</sheetData></worksheet>`)
+	if w.err == nil {
+		w.err = w.sheet.Flush()
+	}
+	if w.err != nil {
+		return w.err
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) write(s string) {
+	if w.err == nil {
+		_, w.err = w.sheet.WriteString(s)
+	}
+}
+
+func writePart(zw *zip.Writer, name, body string) error {
+	pw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(pw, body)
+	return err
+}
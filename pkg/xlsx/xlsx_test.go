@@ -0,0 +1,192 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sheet mirrors the parts of sheet1.xml the writer produces.
+type sheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			T  string `xml:"t,attr"`
+			V  string `xml:"v"`
+			Is struct {
+				T struct {
+					Space string `xml:"space,attr"`
+					Text  string `xml:",chardata"`
+				} `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// parts unzips a workbook, checking every part is well-formed XML.
+func parts(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	out := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		dec := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v\n%s", f.Name, err, body)
+			}
+		}
+		out[f.Name] = body
+	}
+	return out
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, `Members & "Friends"`)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := w.WriteRow([]string{"id", "name", "note"}); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	for _, r := range [][]interface{}{
+		{int64(1), "Ann", nil},
+		{2, " <b>Bob</b> & co ", 1.5},
+		{int64(-9007199254740993), "=SUM(A1:A2)", "bell\x07"},
+	} {
+		if err := w.WriteValues(r); err != nil {
+			t.Fatalf("WriteValues(%v): %v", r, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	files := parts(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(files["xl/workbook.xml"], &wb); err != nil {
+		t.Fatalf("workbook.xml: %v", err)
+	}
+	if len(wb.Sheets) != 1 || wb.Sheets[0].Name != `Members & "Friends"` {
+		t.Errorf("sheets = %+v, want one named %q", wb.Sheets, `Members & "Friends"`)
+	}
+
+	var s sheet
+	if err := xml.Unmarshal(files["xl/worksheets/sheet1.xml"], &s); err != nil {
+		t.Fatalf("sheet1.xml: %v", err)
+	}
+	type cell struct{ t, value string }
+	want := [][]cell{
+		{{"inlineStr", "id"}, {"inlineStr", "name"}, {"inlineStr", "note"}},
+		{{"", "1"}, {"inlineStr", "Ann"}, {"", ""}},
+		{{"", "2"}, {"inlineStr", " <b>Bob</b> & co "}, {"", "1.5"}},
+		{{"", "-9007199254740993"}, {"inlineStr", "=SUM(A1:A2)"}, {"inlineStr", "bell\uFFFD"}},
+	}
+	if len(s.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(s.Rows), len(want))
+	}
+	for i, row := range s.Rows {
+		if wantR := string(rune('1' + i)); row.R != wantR {
+			t.Errorf("row %d: r=%q, want %q", i, row.R, wantR)
+		}
+		got := make([]cell, len(row.Cells))
+		for j, c := range row.Cells {
+			got[j] = cell{c.T, c.V + c.Is.T.Text}
+			if c.T == "inlineStr" && c.Is.T.Space != "preserve" {
+				t.Errorf("row %d cell %d: text does not preserve whitespace", i, j)
+			}
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("row %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Empty")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	var s sheet
+	if err := xml.Unmarshal(parts(t, buf.Bytes())["xl/worksheets/sheet1.xml"], &s); err != nil {
+		t.Fatalf("sheet1.xml: %v", err)
+	}
+	if len(s.Rows) != 0 {
+		t.Errorf("got %d rows, want none", len(s.Rows))
+	}
+}
+
+func TestWriterUnsupportedValue(t *testing.T) {
+	w, err := NewWriter(io.Discard, "Sheet")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	err = w.WriteValues([]interface{}{"ok", true})
+	if err == nil || !strings.Contains(err.Error(), "bool") {
+		t.Fatalf("err = %v, want unsupported bool", err)
+	}
+	// The error sticks: later rows and Close report it instead of writing a broken file.
+	if err2 := w.WriteRow([]string{"more"}); err2 != err {
+		t.Errorf("WriteRow after error = %v, want %v", err2, err)
+	}
+	if err2 := w.Close(); err2 != err {
+		t.Errorf("Close after error = %v, want %v", err2, err)
+	}
+}
+
+// failWriter fails every write after the first n bytes.
+type failWriter struct{ n int }
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	if len(p) > f.n {
+		return 0, io.ErrShortWrite
+	}
+	f.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriterPropagatesWriteErrors(t *testing.T) {
+	// The zip writer buffers, so the failure surfaces from whichever call flushes first.
+	w, err := NewWriter(&failWriter{n: 1000}, "Sheet")
+	for i := 0; i < 1000 && err == nil; i++ {
+		err = w.WriteRow([]string{strings.Repeat("x", 100)})
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != io.ErrShortWrite {
+		t.Errorf("err = %v, want %v", err, io.ErrShortWrite)
+	}
+}